@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"lol-champ-recommender/internal/champions"
+)
+
+func runChampionsSync(ctx context.Context, args []string) error {
+	flags, global := newFlagSet("champions sync")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	_, dbConn, err := global.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer dbConn.Close()
+
+	err = champions.UpsertChampions(ctx, dbConn.Queries)
+	if err != nil {
+		return fmt.Errorf("error updating champions: %w", err)
+	}
+	return nil
+}
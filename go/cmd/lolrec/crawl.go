@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"lol-champ-recommender/db"
+	"lol-champ-recommender/internal/api"
+	"lol-champ-recommender/internal/config"
+	"lol-champ-recommender/internal/crawler"
+	"lol-champ-recommender/internal/database"
+	"lol-champ-recommender/internal/ingest"
+	"lol-champ-recommender/internal/metrics"
+	"lol-champ-recommender/internal/rank"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func runRegionCrawler(ctx context.Context, region string, queries *db.Queries, writer *ingest.BatchWriter, apiKey string, crawlerConfig config.CrawlerConfig) error {
+	client, err := api.NewRiotClient(apiKey, region, ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize Riot API client for %s: %v", region, err)
+	}
+
+	crawler := crawler.Crawler{
+		Queries: queries,
+		Client:  client,
+		Ctx:     ctx,
+		Config:  crawlerConfig,
+		Writer:  writer,
+	}
+	if crawlerConfig.Ranks {
+		crawler.Ranks = &rank.Cache{Queries: queries, Client: client, TTL: crawlerConfig.RankTTL, Budget: client.NewBudget(crawlerConfig.RankShare)}
+	}
+	if crawlerConfig.Timelines {
+		crawler.TimelineBudget = client.NewBudget(crawlerConfig.TimelineShare)
+	}
+	if _, backfill, _ := crawlerConfig.Backfill(); backfill {
+		crawler.BackfillBudget = client.NewBudget(crawlerConfig.BackfillShare)
+	}
+
+	return crawler.RunCrawler(ctx)
+}
+
+func runCrawl(ctx context.Context, args []string) error {
+	flags, global := newFlagSet("crawl")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	cfg, err := global.setup()
+	if err != nil {
+		return err
+	}
+	if err := cfg.Crawler.ValidateSeeds(); err != nil {
+		return usageError{fmt.Errorf("invalid config: %w", err)}
+	}
+
+	dbConn, err := database.Open(ctx, cfg.Database.Options())
+	if err != nil {
+		return err
+	}
+	defer dbConn.Close()
+
+	regions := cfg.Crawler.Regions
+	queries := dbConn.Queries
+
+	// Initialize API client
+	apiKey := cfg.Riot.APIKey
+
+	// Create a cancellable context for all crawlers, cancelled on shutdown signals too
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Matches from every region are written together, the writer outlives the crawlers so it can flush what they queued
+	writer := ingest.NewBatchWriter(dbConn.Pool, cfg.Crawler.BatchSize, cfg.Crawler.FlushInterval)
+	writerCtx, stopWriter := context.WithCancel(context.WithoutCancel(ctx))
+	writerDone := make(chan error, 1)
+	go func() {
+		writerDone <- writer.Run(writerCtx)
+	}()
+	defer func() {
+		stopWriter()
+		if err := <-writerDone; err != nil {
+			slog.Error("Error saving the last matches", "err", err)
+		}
+	}()
+
+	if cfg.Crawler.MetricsAddr != "" {
+		go func() {
+			if err := metrics.Serve(runCtx, cfg.Crawler.MetricsAddr); err != nil {
+				slog.Error("Error serving metrics", "addr", cfg.Crawler.MetricsAddr, "err", err)
+			}
+		}()
+	}
+	go metrics.LogSummary(runCtx, cfg.Crawler.SummaryInterval, func(ctx context.Context) (int64, error) {
+		return queries.CountDuePlayers(ctx, pgtype.Timestamp{Time: time.Now().UTC(), Valid: true})
+	})
+
+	// Create error channel for all crawlers
+	errChan := make(chan error, len(regions))
+
+	// Start a crawler for each region
+	for _, region := range regions {
+		go func(r string) {
+			errChan <- runRegionCrawler(runCtx, r, queries, writer, apiKey, cfg.Crawler)
+		}(region)
+	}
+
+	// Wait for shutdown signal or first error
+	var crawlErr error
+	finished := 0
+	select {
+	case <-ctx.Done():
+		slog.Info("Shutdown signal received, stopping all crawlers")
+		cancel()
+	case crawlErr = <-errChan:
+		slog.Error("Crawler stopped due to error", "err", crawlErr)
+		finished++
+		cancel()
+	}
+
+	// Wait for all crawlers to finish (with a timeout)
+	// Crawlers save where they were before stopping, which is usually quick since in-flight requests are cancelled
+	timeout := time.After(cfg.Crawler.ShutdownTimeout)
+	for finished < len(regions) {
+		select {
+		case err := <-errChan:
+			if err != nil && !errors.Is(err, context.Canceled) {
+				slog.Error("Crawler error during shutdown", "err", err)
+			}
+			finished++
+		case <-timeout:
+			slog.Warn("Not all crawlers stopped in time, forcing exit")
+			return crawlErr
+		}
+	}
+	slog.Info("All crawlers stopped successfully")
+
+	return crawlErr
+}
+
+func runCrawlStatus(ctx context.Context, args []string) error {
+	flags, global := newFlagSet("crawl status")
+	patches := flags.Int("patches", 5, "how many of each server's newest patches to list, 0 for all of them")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *patches < 0 {
+		return usageError{fmt.Errorf("-patches must not be negative")}
+	}
+
+	cfg, dbConn, err := global.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer dbConn.Close()
+
+	status, err := crawler.CrawlStatus(ctx, dbConn.Queries)
+	if err != nil {
+		return err
+	}
+
+	// Matches only store the server, each region crawls the server of its seed account
+	regions := make(map[string]string)
+	for region, account := range cfg.Crawler.SeedAccounts {
+		regions[account.Server] = region
+	}
+
+	fmt.Printf("Players crawled: %d, %d due to be crawled again\n", status.Players, status.DuePlayers)
+	if len(status.Servers) == 0 {
+		fmt.Println("No matches yet")
+		return nil
+	}
+
+	fmt.Printf("\n%-6s %-9s %10s %10s %10s  %-16s  %-16s\n", "Server", "Region", "Matches", "Last hour", "Last day", "Oldest game", "Newest game")
+	totals := db.MatchCountsByServerRow{}
+	for _, server := range status.Servers {
+		fmt.Printf("%-6s %-9s %10d %10d %10d  %-16s  %-16s\n", server.ServerID, regions[server.ServerID],
+			server.Matches, server.LastHour, server.LastDay,
+			server.OldestGameStart.Time.Format("2006-01-02 15:04"), server.NewestGameStart.Time.Format("2006-01-02 15:04"))
+		totals.Matches += server.Matches
+		totals.LastHour += server.LastHour
+		totals.LastDay += server.LastDay
+	}
+	fmt.Printf("%-6s %-9s %10d %10d %10d\n", "Total", "", totals.Matches, totals.LastHour, totals.LastDay)
+
+	if *patches > 0 {
+		fmt.Printf("\nMatches by patch, newest %d per server:\n", *patches)
+	} else {
+		fmt.Println("\nMatches by patch:")
+	}
+	listed := make(map[string]int)
+	for _, patch := range status.Patches {
+		if *patches > 0 && listed[patch.ServerID] >= *patches {
+			continue
+		}
+		listed[patch.ServerID]++
+		fmt.Printf("  %-6s %-8s %10d\n", patch.ServerID, patch.Patch, patch.Matches)
+	}
+
+	serverMatches := make(map[string]int64)
+	for _, server := range status.Servers {
+		serverMatches[server.ServerID] = server.Matches
+	}
+	fmt.Println("\nQueue mix:")
+	for _, queue := range status.Queues {
+		name, ok := api.Queues[int(queue.QueueID)]
+		if !ok {
+			name = "other"
+		}
+		fmt.Printf("  %-6s %-16s %5d %10d %6.1f%%\n", queue.ServerID, name, queue.QueueID, queue.Matches,
+			float64(queue.Matches)/float64(serverMatches[queue.ServerID])*100)
+	}
+	return nil
+}
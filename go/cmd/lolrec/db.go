@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"lol-champ-recommender/internal/database"
+	"lol-champ-recommender/internal/migrate"
+	"lol-champ-recommender/internal/reset"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func runDBMigrate(ctx context.Context, args []string) error {
+	flags, global := newFlagSet("db migrate")
+	steps := flags.Int("steps", 1, "number of migrations to roll back with down")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: lolrec db migrate [flags] up|down|status")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return usageError{fmt.Errorf("expected exactly one of up, down or status")}
+	}
+
+	cfg, err := global.setup()
+	if err != nil {
+		return err
+	}
+
+	dbConn, err := database.Connect(ctx, cfg.Database.Options())
+	if err != nil {
+		return err
+	}
+	defer dbConn.Close()
+
+	return dbConn.WithConn(ctx, func(conn *pgx.Conn) error {
+		return runMigrateCommand(ctx, conn, flags.Arg(0), *steps)
+	})
+}
+
+func runMigrateCommand(ctx context.Context, conn *pgx.Conn, command string, steps int) error {
+	switch command {
+	case "up":
+		applied, err := migrate.Up(ctx, conn)
+		for _, migration := range applied {
+			fmt.Printf("Applied %d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("No pending migrations")
+		}
+	case "down":
+		rolledBack, err := migrate.Down(ctx, conn, steps)
+		for _, migration := range rolledBack {
+			fmt.Printf("Rolled back %d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+	case "status":
+		statuses, err := migrate.Status(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied " + status.AppliedAt.Time.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%d_%s: %s\n", status.Version, status.Name, state)
+		}
+	default:
+		return usageError{fmt.Errorf("unknown migrate command %q", command)}
+	}
+
+	return nil
+}
+
+func runDBReset(ctx context.Context, args []string) error {
+	flags, global := newFlagSet("db reset")
+	tables := flags.String("tables", "", "comma separated tables to delete rows from (default all of "+strings.Join(reset.Tables, ",")+", or matches with -server or -patch)")
+	server := flags.String("server", "", "only delete matches played on this server, e.g. NA1")
+	patch := flags.String("patch", "", "only delete matches played on this patch, e.g. 14.1")
+	olderThan := flags.Duration("older-than", 0, "only delete rows older than this, e.g. 720h")
+	dumpDir := flags.String("dump", "", "write the rows to this directory as JSON lines before deleting them")
+	yes := flags.Bool("yes", false, "delete without asking for confirmation")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	scope := reset.Scope{
+		Tables: reset.Tables,
+		Server: *server,
+		Patch:  *patch,
+	}
+	switch {
+	case *tables != "":
+		scope.Tables = strings.Split(*tables, ",")
+	case *server != "" || *patch != "":
+		scope.Tables = []string{"matches"}
+	}
+	if *olderThan > 0 {
+		scope.Before = time.Now().Add(-*olderThan)
+	}
+	if err := scope.Validate(); err != nil {
+		return usageError{err}
+	}
+
+	_, dbConn, err := global.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer dbConn.Close()
+
+	counts, err := reset.Count(ctx, dbConn.Queries, scope)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("This will delete:")
+	total := int64(0)
+	for _, count := range counts {
+		fmt.Printf("  %-18s %d rows\n", count.Table, count.Rows)
+		total += count.Rows
+	}
+	if total == 0 {
+		fmt.Println("Nothing to delete")
+		return nil
+	}
+
+	if !*yes && !confirm("Delete these rows?") {
+		return fmt.Errorf("reset aborted")
+	}
+
+	deleted, err := reset.Delete(ctx, dbConn.Pool, scope, *dumpDir)
+	if err != nil {
+		return err
+	}
+	if *dumpDir != "" {
+		fmt.Println("Dumped deleted rows to", *dumpDir)
+	}
+	for _, count := range deleted {
+		slog.Info("Deleted rows", "table", count.Table, "rows", count.Rows)
+	}
+	return nil
+}
+
+// confirm asks a yes or no question on stdin, anything but y or yes is a no
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
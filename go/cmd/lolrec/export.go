@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"lol-champ-recommender/internal/database"
+	"lol-champ-recommender/internal/recommender"
+)
+
+type Champion struct {
+	Name  string `json:"name"`
+	ApiID int    `json:"api_id"`
+}
+
+func writeJSONToNext[T any](data T, outputDir string, filename string) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(outputDir, filename), jsonData, 0644)
+}
+
+func writeChampionsToNext(ctx context.Context, dbConn *database.DB, outputDir string) error {
+	dbChampions, err := dbConn.Queries.AllChampions(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting champions: %w", err)
+	}
+
+	championJSONList := make([]Champion, len(dbChampions))
+	for i, champion := range dbChampions {
+		championJSONList[i] = Champion{
+			Name:  champion.Name,
+			ApiID: int(champion.ApiID),
+		}
+	}
+
+	err = writeJSONToNext(championJSONList, outputDir, "champions.json")
+	if err != nil {
+		return fmt.Errorf("error writing champions: %w", err)
+	}
+	return nil
+}
+
+func writeChampionStatsToNext(ctx context.Context, dbConn *database.DB, scope snapshotScope, outputDir string) error {
+	championStats, err := scope.last(ctx, dbConn.Queries)
+	if err != nil {
+		return err
+	}
+
+	championStatsData, err := recommender.UnmarshalChampionStats(championStats.Data)
+	if err != nil {
+		return fmt.Errorf("error unmarshalling champion stats: %w", err)
+	}
+
+	err = writeJSONToNext(championStatsData, outputDir, "champion_stats.json")
+	if err != nil {
+		return fmt.Errorf("error writing champion stats: %w", err)
+	}
+	return nil
+}
+
+func runExport(ctx context.Context, args []string) error {
+	flags, global := newFlagSet("export")
+	snapshot := addSnapshotFlags(flags)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	scope, err := snapshot.scope(0)
+	if err != nil {
+		return err
+	}
+
+	cfg, dbConn, err := global.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer dbConn.Close()
+
+	if err := writeChampionsToNext(ctx, dbConn, cfg.Export.OutputDir); err != nil {
+		return err
+	}
+	return writeChampionStatsToNext(ctx, dbConn, scope, cfg.Export.OutputDir)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"lol-champ-recommender/internal/lcu"
+)
+
+// Stands in for the League client so the champ select integration can be run without a game client.
+// It writes a lockfile that lolrec recommend -lcu -lockfile <path> can connect to.
+func runLCUReplay(ctx context.Context, args []string) error {
+	flags, global := newFlagSet("lcu replay")
+	recordingPath := flags.String("recording", "internal/lcu/testdata/champ_select_session.json", "JSON array of recorded champ select sessions")
+	lockfilePath := flags.String("lockfile", "lcu_replay.lockfile", "where to write the lockfile")
+	interval := flags.Duration("interval", 2*time.Second, "delay between replayed session events")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if _, err := global.setup(); err != nil {
+		return err
+	}
+
+	frames, err := lcu.LoadRecording(*recordingPath)
+	if err != nil {
+		return err
+	}
+
+	const password = "replay"
+	server := httptest.NewTLSServer(lcu.NewReplayHandler(frames, password, *interval))
+	defer server.Close()
+
+	_, portString, _ := strings.Cut(strings.TrimPrefix(server.URL, "https://"), ":")
+	port, err := strconv.Atoi(portString)
+	if err != nil {
+		return fmt.Errorf("error parsing server port: %w", err)
+	}
+
+	lockfile := lcu.Lockfile{
+		ProcessName: "LeagueClient",
+		PID:         os.Getpid(),
+		Port:        port,
+		Password:    password,
+		Protocol:    "https",
+	}
+	if err := os.WriteFile(*lockfilePath, []byte(lockfile.String()), 0644); err != nil {
+		return fmt.Errorf("error writing lockfile: %w", err)
+	}
+	defer os.Remove(*lockfilePath)
+
+	fmt.Printf("Replaying %d sessions on %s, lockfile written to %s\n", len(frames), server.URL, *lockfilePath)
+
+	<-ctx.Done()
+	return nil
+}
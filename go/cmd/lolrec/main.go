@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"lol-champ-recommender/internal/config"
+	"lol-champ-recommender/internal/database"
+	"lol-champ-recommender/internal/logging"
+)
+
+// Exit codes
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+type command struct {
+	// One or two words, e.g. "crawl" or "stats build"
+	name    string
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+var commands = []command{
+	{"crawl", "crawl the Riot API for new matches", runCrawl},
+	{"crawl status", "report how many matches have been crawled by server, patch and queue", runCrawlStatus},
+	{"stats build", "build a champion stats snapshot from the stored matches", runStatsBuild},
+	{"recommend", "recommend champions for a champ select", runRecommend},
+	{"export", "write champions and the latest champion stats to the website", runExport},
+	{"evaluate", "predict held out matches with the latest champion stats", runEvaluate},
+	{"db migrate", "apply, roll back or list schema migrations", runDBMigrate},
+	{"db reset", "delete matches, champion stats or search log rows, optionally scoped", runDBReset},
+	{"matches reprocess", "rebuild matches and participants from the raw match archive", runMatchesReprocess},
+	{"matches validate", "check stored matches for bad data and optionally quarantine them", runMatchesValidate},
+	{"champions sync", "upsert champions from Data Dragon for the latest stored patch", runChampionsSync},
+	{"lcu replay", "stand in for the League client by replaying a recorded champ select", runLCUReplay},
+}
+
+// usageError is returned for bad arguments, which exit with exitUsage instead of exitError
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string {
+	return e.err.Error()
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: lolrec <command> [flags]")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-18s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nRun lolrec <command> -h for the command's flags.")
+}
+
+// findCommand matches the longest command name at the start of args and returns the remaining args
+func findCommand(args []string) (command, []string, bool) {
+	for _, words := range []int{2, 1} {
+		if len(args) < words {
+			continue
+		}
+		name := strings.Join(args[:words], " ")
+		for _, cmd := range commands {
+			if cmd.name == name {
+				return cmd, args[words:], true
+			}
+		}
+	}
+	return command{}, nil, false
+}
+
+func main() {
+	cmd, args, ok := findCommand(os.Args[1:])
+	if !ok {
+		usage()
+		os.Exit(exitUsage)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := cmd.run(ctx, args)
+	stop()
+
+	var usageErr usageError
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		os.Exit(exitOK)
+	case errors.As(err, &usageErr):
+		fmt.Fprintf(os.Stderr, "lolrec %s: %v\n", cmd.name, err)
+		os.Exit(exitUsage)
+	default:
+		fmt.Fprintf(os.Stderr, "lolrec %s: %v\n", cmd.name, err)
+		os.Exit(exitError)
+	}
+}
+
+// globalFlags are registered on every command
+type globalFlags struct {
+	config  *config.Flags
+	logFile string
+	quiet   bool
+}
+
+func newFlagSet(name string) (*flag.FlagSet, *globalFlags) {
+	flags := flag.NewFlagSet("lolrec "+name, flag.ContinueOnError)
+	global := &globalFlags{
+		config: config.RegisterFlags(flags),
+	}
+	flags.StringVar(&global.logFile, "log-file", "", "append log output to this file instead of stderr")
+	flags.BoolVar(&global.quiet, "quiet", false, "discard log output")
+	return flags, global
+}
+
+// parseFlags parses args and reports bad flags as usage errors
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return usageError{err}
+	}
+	return nil
+}
+
+// setup loads the config and sets up logging from it and the logging flags
+func (g *globalFlags) setup() (*config.Config, error) {
+	cfg, err := g.config.Load()
+	if err != nil {
+		return nil, usageError{err}
+	}
+
+	var output io.Writer = os.Stderr
+	switch {
+	case g.quiet:
+		output = io.Discard
+	case g.logFile != "":
+		file, err := os.OpenFile(g.logFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("error opening log file: %w", err)
+		}
+		output = file
+	}
+	if err := logging.Setup(output, cfg.Log.Level, cfg.Log.Format); err != nil {
+		return nil, usageError{err}
+	}
+	return cfg, nil
+}
+
+// open sets up the command and connects to the database, applying pending migrations
+func (g *globalFlags) open(ctx context.Context) (*config.Config, *database.DB, error) {
+	cfg, err := g.setup()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dbConn, err := database.Open(ctx, cfg.Database.Options())
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, dbConn, nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"lol-champ-recommender/db"
+	"lol-champ-recommender/internal/champions"
+	"lol-champ-recommender/internal/crawler"
+	"lol-champ-recommender/internal/ingest"
+	"lol-champ-recommender/internal/validate"
+)
+
+// Rebuilds matches and participants from the raw_matches archive without calling the Riot API
+func runMatchesReprocess(ctx context.Context, args []string) error {
+	flags, global := newFlagSet("matches reprocess")
+	pageSize := flags.Int("page-size", 500, "how many archived matches to read and write at a time")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *pageSize < 1 {
+		return usageError{fmt.Errorf("-page-size must be at least 1")}
+	}
+
+	cfg, dbConn, err := global.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer dbConn.Close()
+
+	total, err := dbConn.Queries.CountRawMatches(ctx)
+	if err != nil {
+		return fmt.Errorf("error counting archived matches: %w", err)
+	}
+	fmt.Printf("Reprocessing %d archived matches\n", total)
+
+	writer := ingest.NewBatchWriter(dbConn.Pool, *pageSize, cfg.Crawler.FlushInterval)
+	writer.Replace = true
+
+	processed, skipped := 0, 0
+	lastMatchID := ""
+	for {
+		rawMatches, err := dbConn.Queries.RawMatchesAfter(ctx, db.RawMatchesAfterParams{
+			MatchID: lastMatchID,
+			Limit:   int32(*pageSize),
+		})
+		if err != nil {
+			return fmt.Errorf("error reading archived matches: %w", err)
+		}
+		if len(rawMatches) == 0 {
+			break
+		}
+
+		for _, rawMatch := range rawMatches {
+			lastMatchID = rawMatch.MatchID
+
+			matchData, err := ingest.Decompress(rawMatch.Data)
+			if err != nil {
+				return fmt.Errorf("error reading %s: %w", rawMatch.MatchID, err)
+			}
+
+			match, err := crawler.BuildMatch(matchData)
+			if err != nil {
+				slog.Warn("Skipping match", "match_id", rawMatch.MatchID, "err", err)
+				skipped++
+				continue
+			}
+
+			writer.Add(ctx, match)
+			processed++
+		}
+
+		if err := writer.Flush(ctx); err != nil {
+			return err
+		}
+		fmt.Printf("Reprocessed %d/%d matches\n", processed+skipped, total)
+	}
+
+	fmt.Printf("Rebuilt %d matches, skipped %d\n", processed, skipped)
+	return nil
+}
+
+// Checks the stored matches for data that would skew or break stats builds
+func runMatchesValidate(ctx context.Context, args []string) error {
+	flags, global := newFlagSet("matches validate")
+	quarantine := flags.Bool("quarantine", false, "move invalid matches to quarantined_matches")
+	examples := flags.Int("examples", 5, "how many match ids to list for each problem")
+	pageSize := flags.Int("page-size", 1000, "how many matches to read at a time")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *pageSize < 1 {
+		return usageError{fmt.Errorf("-page-size must be at least 1")}
+	}
+	if *examples < 0 {
+		return usageError{fmt.Errorf("-examples must not be negative")}
+	}
+
+	_, dbConn, err := global.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer dbConn.Close()
+
+	// A champion released since the last sync would otherwise count as unknown
+	if err := champions.UpsertChampions(ctx, dbConn.Queries); err != nil {
+		slog.Warn("Error syncing champions, checking against the stored ones", "err", err)
+	}
+
+	report, err := validate.Run(ctx, dbConn.Pool, validate.Options{
+		Examples:   *examples,
+		Quarantine: *quarantine,
+		PageSize:   *pageSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Checked %d matches, %d invalid\n", report.Matches, report.Invalid)
+	if report.NoChampions {
+		fmt.Println("No champions stored, unknown champions weren't checked. Run champions sync first.")
+	}
+	for _, problem := range validate.Problems {
+		count := report.Counts[problem]
+		if count == 0 {
+			continue
+		}
+		fmt.Printf("  %-18s %d", problem, count)
+		if len(report.Examples[problem]) > 0 {
+			fmt.Printf("  e.g. %s", strings.Join(report.Examples[problem], ", "))
+		}
+		fmt.Println()
+	}
+	if *quarantine {
+		fmt.Printf("Quarantined %d matches\n", report.Quarantined)
+	} else if report.Invalid > 0 {
+		fmt.Println("Run with -quarantine to move them out of matches")
+	}
+	return nil
+}
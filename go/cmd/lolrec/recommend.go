@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"lol-champ-recommender/db"
+	"lol-champ-recommender/internal/api"
+	"lol-champ-recommender/internal/lcu"
+	"lol-champ-recommender/internal/mastery"
+	"lol-champ-recommender/internal/recommender"
+)
+
+// Queue id of ARAM, whose snapshots are built with stats build -queue 450
+const aramQueue = 450
+
+// Recommends champions live as the League client's champ select session changes
+func recommendFromLCU(ctx context.Context, queries *db.Queries, championStats recommender.ChampionDataMap, options recommender.RecommendOptions, aram bool, lockfilePath string) error {
+	lockfile, err := lcu.ReadLockfile(lockfilePath)
+	if err != nil {
+		return err
+	}
+	client := lcu.NewClient(lockfile)
+
+	fmt.Println("Waiting for champ select...")
+
+	var lastChampSelect recommender.ChampSelect
+	return client.WatchSession(ctx, func(event lcu.SessionEvent) error {
+		if event.EventType == "Delete" {
+			fmt.Println("Champ select ended")
+			lastChampSelect = recommender.ChampSelect{}
+			return nil
+		}
+
+		// The session updates every time the timer ticks, only recommend when picks or bans change
+		champSelect := event.Session.ChampSelect()
+		if reflect.DeepEqual(champSelect, lastChampSelect) || event.Session.LocalPlayerPicked() {
+			return nil
+		}
+		lastChampSelect = champSelect
+
+		r, err := recommend(championStats, champSelect, options, aram)
+		if err != nil {
+			return fmt.Errorf("error recommending champions: %w", err)
+		}
+
+		return recommender.FormatAnswer(ctx, queries, champSelect, r)
+	})
+}
+
+// recommend picks from the bench in ARAM and from every champion otherwise
+func recommend(championStats recommender.ChampionDataMap, champSelect recommender.ChampSelect, options recommender.RecommendOptions, aram bool) ([]recommender.ChampionPerformance, error) {
+	if aram {
+		return recommender.RecommendAram(championStats, champSelect)
+	}
+	return recommender.RecommendChampions(championStats, champSelect, options)
+}
+
+func runRecommend(ctx context.Context, args []string) error {
+	flags, global := newFlagSet("recommend")
+	useLCU := flags.Bool("lcu", false, "follow champ select in the running League client")
+	lockfilePath := flags.String("lockfile", lcu.DefaultLockfilePath(), "path to the League client lockfile")
+	poolName := flags.String("pool", "", "only recommend champions from this champion pool")
+	riotID := flags.String("riot-id", "", "favor champions this player (Name#TAG) is comfortable on")
+	region := flags.String("region", "", "regional route of the player's account (default recommender.region)")
+	server := flags.String("server", "", "server the player plays on (default recommender.server)")
+	recentCount := flags.Int("recent", 20, "number of the player's recent ranked matches to consider")
+	comfortWeight := flags.Float64("comfort-weight", 0.05, "the most a champion's score is raised by the player's comfort on it")
+	counterabilityWeight := flags.Float64("counterability-weight", 0, "how much to penalize champions that are easy to counter pick, useful when picking early")
+	draftSide := flags.String("draft-side", "", "simulate the rest of the draft for this side (blue or red)")
+	draftDepth := flags.Int("draft-depth", 2, "how many picks after ours to simulate")
+	draftBreadth := flags.Int("draft-breadth", 8, "how many responses to consider for each simulated pick")
+	aram := flags.Bool("aram", false, "rank the rolled and bench champions with ARAM stats instead of drafting")
+	rolled := flags.String("rolled", "", "with -aram and without -lcu, the champion we rolled")
+	bench := flags.String("bench", "", "with -aram and without -lcu, comma separated champions on the bench")
+	allies := flags.String("allies", "", "without -lcu, comma separated champions our teammates have")
+	enemies := flags.String("enemies", "", "without -lcu or -aram, comma separated champions the enemies picked")
+	bans := flags.String("bans", "", "without -lcu or -aram, comma separated banned champions")
+	snapshot := addSnapshotFlags(flags)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *aram && (*draftSide != "" || *poolName != "" || *riotID != "") {
+		return usageError{fmt.Errorf("-aram can't be combined with -draft-side, -pool or -riot-id")}
+	}
+	if *aram && (*enemies != "" || *bans != "") {
+		return usageError{fmt.Errorf("-enemies and -bans don't apply to -aram")}
+	}
+	if !*aram && (*rolled != "" || *bench != "") {
+		return usageError{fmt.Errorf("-rolled and -bench only apply to -aram")}
+	}
+	if *useLCU && (*allies != "" || *enemies != "" || *bans != "" || *rolled != "" || *bench != "") {
+		return usageError{fmt.Errorf("-lcu reads the champ select from the client, it can't be combined with -allies, -enemies, -bans, -rolled or -bench")}
+	}
+	queue := 0
+	if *aram {
+		if *snapshot.queue != 0 && *snapshot.queue != aramQueue {
+			return usageError{fmt.Errorf("-aram can't be combined with -queue %d", *snapshot.queue)}
+		}
+		queue = aramQueue
+	} else if *snapshot.queue == aramQueue {
+		return usageError{fmt.Errorf("ARAM stats are only used with -aram")}
+	}
+	scope, err := snapshot.scope(queue)
+	if err != nil {
+		return err
+	}
+
+	options := recommender.RecommendOptions{
+		CounterabilityWeight: *counterabilityWeight,
+	}
+	if *draftSide != "" {
+		side, err := recommender.ParseSide(*draftSide)
+		if err != nil {
+			return usageError{err}
+		}
+		options.Draft = &recommender.DraftOptions{
+			Side:    side,
+			Depth:   *draftDepth,
+			Breadth: *draftBreadth,
+		}
+	}
+
+	cfg, dbConn, err := global.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer dbConn.Close()
+
+	recordWithStats, err := scope.last(ctx, dbConn.Queries)
+	if err != nil {
+		return err
+	}
+
+	championStats, err := recommender.UnmarshalChampionStats(recordWithStats.Data)
+	if err != nil {
+		return fmt.Errorf("error unmarshalling champion stats: %w", err)
+	}
+
+	if *poolName != "" {
+		options.Pool, err = recommender.LoadChampionPool(ctx, dbConn.Queries, cfg.Recommender.ChampionPoolsFile, *poolName)
+		if err != nil {
+			return fmt.Errorf("error loading champion pool: %w", err)
+		}
+	}
+
+	if *riotID != "" {
+		if *region == "" {
+			*region = cfg.Recommender.Region
+		}
+		if *server == "" {
+			*server = cfg.Recommender.Server
+		}
+		client, err := api.NewRiotClient(cfg.Riot.APIKey, *region, ctx)
+		if err != nil {
+			return fmt.Errorf("error initializing Riot API client: %w", err)
+		}
+
+		options.Comfort, err = mastery.PlayerComfort(client, *server, *riotID, *recentCount)
+		if err != nil {
+			return fmt.Errorf("error getting player comfort: %w", err)
+		}
+		options.ComfortWeight = *comfortWeight
+	}
+
+	if *useLCU {
+		err = recommendFromLCU(ctx, dbConn.Queries, championStats, options, *aram, *lockfilePath)
+		if err != nil && ctx.Err() == nil {
+			return fmt.Errorf("error following champ select: %w", err)
+		}
+		return nil
+	}
+
+	var champSelect recommender.ChampSelect
+	if *aram {
+		champSelect, err = aramChampSelect(ctx, dbConn.Queries, *rolled, *bench, *allies)
+	} else {
+		champSelect, err = draftChampSelect(ctx, dbConn.Queries, *bans, *allies, *enemies)
+	}
+	if err != nil {
+		return err
+	}
+
+	r, err := recommend(championStats, champSelect, options, *aram)
+	if err != nil {
+		return fmt.Errorf("error recommending champions: %w", err)
+	}
+
+	err = recommender.FormatAnswer(ctx, dbConn.Queries, champSelect, r)
+	if err != nil {
+		return fmt.Errorf("error formatting answer: %w", err)
+	}
+	return nil
+}
+
+// draftChampSelect builds a champ select from champion names, any of them can be empty
+func draftChampSelect(ctx context.Context, queries *db.Queries, bans, allies, enemies string) (recommender.ChampSelect, error) {
+	var champSelect recommender.ChampSelect
+	var err error
+	if champSelect.Bans, err = recommender.NamesToIDs(ctx, queries, splitList(bans)); err != nil {
+		return recommender.ChampSelect{}, usageError{err}
+	}
+	if champSelect.Allies, err = recommender.NamesToIDs(ctx, queries, splitList(allies)); err != nil {
+		return recommender.ChampSelect{}, usageError{err}
+	}
+	if champSelect.Enemies, err = recommender.NamesToIDs(ctx, queries, splitList(enemies)); err != nil {
+		return recommender.ChampSelect{}, usageError{err}
+	}
+	return champSelect, nil
+}
+
+// aramChampSelect builds an ARAM champ select from champion names
+func aramChampSelect(ctx context.Context, queries *db.Queries, rolled, bench, allies string) (recommender.ChampSelect, error) {
+	if rolled == "" && bench == "" {
+		return recommender.ChampSelect{}, usageError{fmt.Errorf("-aram needs -rolled or -bench when not following the client with -lcu")}
+	}
+
+	champSelect := recommender.ChampSelect{Bans: []int32{}, Enemies: []int32{}}
+	var err error
+	if champSelect.Bench, err = recommender.NamesToIDs(ctx, queries, splitList(bench)); err != nil {
+		return recommender.ChampSelect{}, usageError{err}
+	}
+	if champSelect.Allies, err = recommender.NamesToIDs(ctx, queries, splitList(allies)); err != nil {
+		return recommender.ChampSelect{}, usageError{err}
+	}
+	if rolled != "" {
+		ids, err := recommender.NamesToIDs(ctx, queries, []string{rolled})
+		if err != nil {
+			return recommender.ChampSelect{}, usageError{err}
+		}
+		champSelect.Rolled = ids[0]
+	}
+	return champSelect, nil
+}
+
+// splitList splits a comma separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"lol-champ-recommender/db"
+	"lol-champ-recommender/internal/api"
+	"lol-champ-recommender/internal/rank"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// snapshotFlags pick the champion stats snapshot a command reads by the bracket and queue it was built for
+type snapshotFlags struct {
+	minTier *string
+	maxTier *string
+	queue   *int
+}
+
+func addSnapshotFlags(flags *flag.FlagSet) snapshotFlags {
+	return snapshotFlags{
+		minTier: flags.String("min-tier", "", "use the snapshot built with this stats build -min-tier"),
+		maxTier: flags.String("max-tier", "", "use the snapshot built with this stats build -max-tier"),
+		queue:   flags.Int("queue", 0, "use the snapshot built with this stats build -queue (default the latest non-ARAM one)"),
+	}
+}
+
+// scope validates the flags, queue overrides -queue when it isn't 0
+func (f snapshotFlags) scope(queue int) (snapshotScope, error) {
+	bracket, err := rank.ParseBracket(*f.minTier, *f.maxTier)
+	if err != nil {
+		return snapshotScope{}, usageError{err}
+	}
+	if queue == 0 {
+		queue = *f.queue
+	}
+	if _, ok := api.Queues[queue]; queue != 0 && !ok {
+		return snapshotScope{}, usageError{fmt.Errorf("unknown queue %d, expected one of %v", queue, api.QueueIDs())}
+	}
+	scope := snapshotScope{Bracket: bracket, Queue: queue}
+	if *f.minTier != "" {
+		scope.buildFlags += " -min-tier " + *f.minTier
+	}
+	if *f.maxTier != "" {
+		scope.buildFlags += " -max-tier " + *f.maxTier
+	}
+	if queue != 0 {
+		scope.buildFlags += fmt.Sprintf(" -queue %d", queue)
+	}
+	return scope, nil
+}
+
+type snapshotScope struct {
+	Bracket rank.Bracket
+	// 0 for the latest snapshot of any queue but ARAM
+	Queue int
+	// The stats build flags that make a snapshot for the scope
+	buildFlags string
+}
+
+// last reads the latest snapshot built for the scope's bracket and queue
+func (s snapshotScope) last(ctx context.Context, queries *db.Queries) (db.ChampionStat, error) {
+	snapshot, err := queries.LastChampionStatsForBracket(ctx, db.LastChampionStatsForBracketParams{
+		MinRank: s.Bracket.Min,
+		MaxRank: s.Bracket.Max,
+		QueueID: pgtype.Int4{Int32: int32(s.Queue), Valid: s.Queue != 0},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return db.ChampionStat{}, fmt.Errorf("no champion stats for %s, build them with stats build%s", s, s.buildFlags)
+	}
+	if err != nil {
+		return db.ChampionStat{}, fmt.Errorf("error getting last champion stats: %w", err)
+	}
+	return snapshot, nil
+}
+
+func (s snapshotScope) String() string {
+	if s.Queue == 0 {
+		return s.Bracket.String()
+	}
+	return s.Bracket.String() + " in " + api.Queues[s.Queue]
+}
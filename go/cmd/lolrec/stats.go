@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"lol-champ-recommender/db"
+	"lol-champ-recommender/internal/api"
+	"lol-champ-recommender/internal/champions"
+	"lol-champ-recommender/internal/rank"
+	"lol-champ-recommender/internal/stats"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func runStatsBuild(ctx context.Context, args []string) error {
+	flags, global := newFlagSet("stats build")
+	percentile := flags.Int("percentile", 100, "only use the oldest percentile of matches, leaving the rest for evaluate")
+	minTier := flags.String("min-tier", "", "only use matches whose average rank is at least this tier, e.g. diamond")
+	maxTier := flags.String("max-tier", "", "only use matches whose average rank is at most this tier")
+	queue := flags.Int("queue", 0, "only use matches from this queue id, e.g. 420 for ranked solo/duo, required once more than one queue has been crawled")
+	includeRemakes := flags.Bool("include-remakes", false, "also use remakes, aborted games are never used")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *percentile < 1 || *percentile > 100 {
+		return usageError{fmt.Errorf("-percentile must be between 1 and 100")}
+	}
+	bracket, err := rank.ParseBracket(*minTier, *maxTier)
+	if err != nil {
+		return usageError{err}
+	}
+	queueID := pgtype.Int4{Int32: int32(*queue), Valid: *queue != 0}
+	if _, ok := api.Queues[*queue]; queueID.Valid && !ok {
+		return usageError{fmt.Errorf("unknown queue %d, expected one of %v", *queue, api.QueueIDs())}
+	}
+
+	_, dbConn, err := global.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer dbConn.Close()
+
+	if !queueID.Valid {
+		// Pooling queues mixes e.g. solo/duo with flex or ARAM, whose winrates don't carry over
+		queues, err := dbConn.Queries.MatchQueueIDs(ctx)
+		if err != nil {
+			return fmt.Errorf("error getting crawled queues: %w", err)
+		}
+		if len(queues) > 1 {
+			return usageError{fmt.Errorf("matches from queues %v have been crawled, pick one with -queue", queues)}
+		}
+	}
+
+	err = champions.UpsertChampions(ctx, dbConn.Queries)
+	if err != nil {
+		return fmt.Errorf("error upserting champions: %w", err)
+	}
+
+	result, err := stats.Build(ctx, dbConn.Queries, stats.BuildOptions{
+		Percentile:     int32(*percentile),
+		Bracket:        bracket,
+		QueueID:        queueID,
+		IncludeRemakes: *includeRemakes,
+	})
+	if err != nil {
+		return err
+	}
+
+	queueName := "every queue"
+	if queueID.Valid {
+		queueName = api.Queues[*queue]
+	}
+	fmt.Println("Created champion stats for", bracket, "in", queueName, "from", result.Matches, "matches")
+	if result.Excluded["remake"] > 0 || result.Excluded["aborted"] > 0 {
+		fmt.Printf("Excluded %d remakes and %d aborted games\n", result.Excluded["remake"], result.Excluded["aborted"])
+	}
+	if result.Excluded["invalid"] > 0 {
+		fmt.Printf("Excluded %d invalid matches, run matches validate to see why\n", result.Excluded["invalid"])
+	}
+	return nil
+}
+
+func runEvaluate(ctx context.Context, args []string) error {
+	flags, global := newFlagSet("evaluate")
+	queue := flags.Int("queue", 0, "evaluate the latest snapshot built for this queue id, e.g. 450 for ARAM (default the latest non-ARAM snapshot)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	_, dbConn, err := global.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer dbConn.Close()
+
+	var snapshot db.ChampionStat
+	if *queue != 0 {
+		snapshot, err = dbConn.Queries.LastChampionStatsForQueue(ctx, pgtype.Int4{Int32: int32(*queue), Valid: true})
+	} else {
+		snapshot, err = dbConn.Queries.LastChampionStats(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("error getting last champion stats: %w", err)
+	}
+
+	evaluation, err := stats.Evaluate(ctx, dbConn.Queries, snapshot)
+	if err != nil {
+		return err
+	}
+
+	if evaluation.Matches == 0 {
+		fmt.Println("No held out matches, build stats with -percentile below 100 to leave some out")
+		return nil
+	}
+
+	fmt.Printf("Evaluated %d matches newer than match %d\n", evaluation.Matches, snapshot.LastMatchID)
+	fmt.Printf("Accuracy: %.2f%%\n", evaluation.Accuracy()*100)
+	fmt.Printf("Brier score: %.4f\n", evaluation.BrierScore)
+	fmt.Printf("Log loss: %.4f\n", evaluation.LogLoss)
+	return nil
+}
@@ -7,29 +7,103 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const championStatsBefore = `-- name: ChampionStatsBefore :many
+SELECT id, data, last_match_id, created_at, min_rank, max_rank, queue_id FROM champion_stats
+WHERE $1::TIMESTAMP IS NULL OR created_at < $1
+ORDER BY id
+`
+
+func (q *Queries) ChampionStatsBefore(ctx context.Context, before pgtype.Timestamp) ([]ChampionStat, error) {
+	rows, err := q.db.Query(ctx, championStatsBefore, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ChampionStat
+	for rows.Next() {
+		var i ChampionStat
+		if err := rows.Scan(
+			&i.ID,
+			&i.Data,
+			&i.LastMatchID,
+			&i.CreatedAt,
+			&i.MinRank,
+			&i.MaxRank,
+			&i.QueueID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countChampionStatsBefore = `-- name: CountChampionStatsBefore :one
+SELECT COUNT(*) FROM champion_stats
+WHERE $1::TIMESTAMP IS NULL OR created_at < $1
+`
+
+func (q *Queries) CountChampionStatsBefore(ctx context.Context, before pgtype.Timestamp) (int64, error) {
+	row := q.db.QueryRow(ctx, countChampionStatsBefore, before)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createChampionStats = `-- name: CreateChampionStats :exec
 INSERT INTO champion_stats (
   data,
-  last_match_id
-) VALUES ($1, $2)
+  last_match_id,
+  min_rank,
+  max_rank,
+  queue_id
+) VALUES ($1, $2, $3, $4, $5)
 `
 
 type CreateChampionStatsParams struct {
 	Data        []byte
 	LastMatchID int32
+	MinRank     pgtype.Int4
+	MaxRank     pgtype.Int4
+	QueueID     pgtype.Int4
 }
 
 func (q *Queries) CreateChampionStats(ctx context.Context, arg CreateChampionStatsParams) error {
-	_, err := q.db.Exec(ctx, createChampionStats, arg.Data, arg.LastMatchID)
+	_, err := q.db.Exec(ctx, createChampionStats,
+		arg.Data,
+		arg.LastMatchID,
+		arg.MinRank,
+		arg.MaxRank,
+		arg.QueueID,
+	)
 	return err
 }
 
+const deleteChampionStatsBefore = `-- name: DeleteChampionStatsBefore :execrows
+DELETE FROM champion_stats
+WHERE $1::TIMESTAMP IS NULL OR created_at < $1
+`
+
+func (q *Queries) DeleteChampionStatsBefore(ctx context.Context, before pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteChampionStatsBefore, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const lastChampionStats = `-- name: LastChampionStats :one
-SELECT id, data, last_match_id, created_at FROM champion_stats ORDER BY created_at DESC LIMIT 1
+SELECT id, data, last_match_id, created_at, min_rank, max_rank, queue_id FROM champion_stats WHERE queue_id IS DISTINCT FROM 450 ORDER BY created_at DESC LIMIT 1
 `
 
+// ARAM (queue 450) snapshots are left out, they are only for recommend -aram
 func (q *Queries) LastChampionStats(ctx context.Context) (ChampionStat, error) {
 	row := q.db.QueryRow(ctx, lastChampionStats)
 	var i ChampionStat
@@ -38,6 +112,58 @@ func (q *Queries) LastChampionStats(ctx context.Context) (ChampionStat, error) {
 		&i.Data,
 		&i.LastMatchID,
 		&i.CreatedAt,
+		&i.MinRank,
+		&i.MaxRank,
+		&i.QueueID,
+	)
+	return i, err
+}
+
+const lastChampionStatsForBracket = `-- name: LastChampionStatsForBracket :one
+SELECT id, data, last_match_id, created_at, min_rank, max_rank, queue_id FROM champion_stats
+WHERE min_rank IS NOT DISTINCT FROM $1::INTEGER
+  AND max_rank IS NOT DISTINCT FROM $2::INTEGER
+  AND (($3::INTEGER IS NULL AND queue_id IS DISTINCT FROM 450) OR queue_id = $3)
+ORDER BY created_at DESC LIMIT 1
+`
+
+type LastChampionStatsForBracketParams struct {
+	MinRank pgtype.Int4
+	MaxRank pgtype.Int4
+	QueueID pgtype.Int4
+}
+
+// Only snapshots built for exactly this bracket, without a queue ARAM snapshots are left out like in LastChampionStats
+func (q *Queries) LastChampionStatsForBracket(ctx context.Context, arg LastChampionStatsForBracketParams) (ChampionStat, error) {
+	row := q.db.QueryRow(ctx, lastChampionStatsForBracket, arg.MinRank, arg.MaxRank, arg.QueueID)
+	var i ChampionStat
+	err := row.Scan(
+		&i.ID,
+		&i.Data,
+		&i.LastMatchID,
+		&i.CreatedAt,
+		&i.MinRank,
+		&i.MaxRank,
+		&i.QueueID,
+	)
+	return i, err
+}
+
+const lastChampionStatsForQueue = `-- name: LastChampionStatsForQueue :one
+SELECT id, data, last_match_id, created_at, min_rank, max_rank, queue_id FROM champion_stats WHERE queue_id = $1 ORDER BY created_at DESC LIMIT 1
+`
+
+func (q *Queries) LastChampionStatsForQueue(ctx context.Context, queueID pgtype.Int4) (ChampionStat, error) {
+	row := q.db.QueryRow(ctx, lastChampionStatsForQueue, queueID)
+	var i ChampionStat
+	err := row.Scan(
+		&i.ID,
+		&i.Data,
+		&i.LastMatchID,
+		&i.CreatedAt,
+		&i.MinRank,
+		&i.MaxRank,
+		&i.QueueID,
 	)
 	return i, err
 }
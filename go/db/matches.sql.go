@@ -22,6 +22,26 @@ func (q *Queries) AnyMatchesFromServer(ctx context.Context, serverID string) (bo
 	return exists, err
 }
 
+const countMatchesInScope = `-- name: CountMatchesInScope :one
+SELECT COUNT(*) FROM matches
+WHERE ($1::TEXT IS NULL OR server_id = $1)
+  AND ($2::TEXT IS NULL OR game_version LIKE $2 || '.%')
+  AND ($3::TIMESTAMP IS NULL OR game_start < $3)
+`
+
+type CountMatchesInScopeParams struct {
+	ServerID pgtype.Text
+	Patch    pgtype.Text
+	Before   pgtype.Timestamp
+}
+
+func (q *Queries) CountMatchesInScope(ctx context.Context, arg CountMatchesInScopeParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countMatchesInScope, arg.ServerID, arg.Patch, arg.Before)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createMatch = `-- name: CreateMatch :exec
 INSERT INTO matches (
     match_id, 
@@ -39,9 +59,12 @@ INSERT INTO matches (
     red_2_champion_id, 
     red_3_champion_id, 
     red_4_champion_id, 
-    red_5_champion_id
+    red_5_champion_id,
+    average_rank,
+    game_duration,
+    outcome
   ) 
-  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16) RETURNING id
+  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19) RETURNING id
 `
 
 type CreateMatchParams struct {
@@ -61,6 +84,9 @@ type CreateMatchParams struct {
 	Red3ChampionID  int32
 	Red4ChampionID  int32
 	Red5ChampionID  int32
+	AverageRank     pgtype.Int4
+	GameDuration    pgtype.Int4
+	Outcome         string
 }
 
 func (q *Queries) CreateMatch(ctx context.Context, arg CreateMatchParams) error {
@@ -81,10 +107,61 @@ func (q *Queries) CreateMatch(ctx context.Context, arg CreateMatchParams) error
 		arg.Red3ChampionID,
 		arg.Red4ChampionID,
 		arg.Red5ChampionID,
+		arg.AverageRank,
+		arg.GameDuration,
+		arg.Outcome,
 	)
 	return err
 }
 
+const deleteMatchesInScope = `-- name: DeleteMatchesInScope :execrows
+DELETE FROM matches
+WHERE ($1::TEXT IS NULL OR server_id = $1)
+  AND ($2::TEXT IS NULL OR game_version LIKE $2 || '.%')
+  AND ($3::TIMESTAMP IS NULL OR game_start < $3)
+`
+
+type DeleteMatchesInScopeParams struct {
+	ServerID pgtype.Text
+	Patch    pgtype.Text
+	Before   pgtype.Timestamp
+}
+
+func (q *Queries) DeleteMatchesInScope(ctx context.Context, arg DeleteMatchesInScopeParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteMatchesInScope, arg.ServerID, arg.Patch, arg.Before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const existingMatchIDs = `-- name: ExistingMatchIDs :many
+SELECT match_id FROM matches WHERE match_id = ANY($1::TEXT[])
+UNION ALL
+SELECT match_id FROM quarantined_matches WHERE match_id = ANY($1::TEXT[])
+`
+
+// Quarantined matches count as existing so they aren't crawled again
+func (q *Queries) ExistingMatchIDs(ctx context.Context, matchIds []string) ([]string, error) {
+	rows, err := q.db.Query(ctx, existingMatchIDs, matchIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var match_id string
+		if err := rows.Scan(&match_id); err != nil {
+			return nil, err
+		}
+		items = append(items, match_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const gameVersions = `-- name: GameVersions :many
 SELECT DISTINCT game_version FROM matches
 `
@@ -110,7 +187,7 @@ func (q *Queries) GameVersions(ctx context.Context) ([]string, error) {
 }
 
 const lastMatch = `-- name: LastMatch :one
-SELECT id, match_id, game_start, game_version, winning_team, queue_id, server_id, red_1_champion_id, red_2_champion_id, red_3_champion_id, red_4_champion_id, red_5_champion_id, blue_1_champion_id, blue_2_champion_id, blue_3_champion_id, blue_4_champion_id, blue_5_champion_id, created_at FROM matches ORDER BY created_at DESC LIMIT 1
+SELECT id, match_id, game_start, game_version, winning_team, queue_id, server_id, red_1_champion_id, red_2_champion_id, red_3_champion_id, red_4_champion_id, red_5_champion_id, blue_1_champion_id, blue_2_champion_id, blue_3_champion_id, blue_4_champion_id, blue_5_champion_id, created_at, average_rank, game_duration, outcome FROM matches ORDER BY created_at DESC LIMIT 1
 `
 
 func (q *Queries) LastMatch(ctx context.Context) (Match, error) {
@@ -135,6 +212,9 @@ func (q *Queries) LastMatch(ctx context.Context) (Match, error) {
 		&i.Blue4ChampionID,
 		&i.Blue5ChampionID,
 		&i.CreatedAt,
+		&i.AverageRank,
+		&i.GameDuration,
+		&i.Outcome,
 	)
 	return i, err
 }
@@ -164,7 +244,7 @@ func (q *Queries) LastMatchesFromServer(ctx context.Context, serverID string) ([
 }
 
 const match = `-- name: Match :one
-SELECT id, match_id, game_start, game_version, winning_team, queue_id, server_id, red_1_champion_id, red_2_champion_id, red_3_champion_id, red_4_champion_id, red_5_champion_id, blue_1_champion_id, blue_2_champion_id, blue_3_champion_id, blue_4_champion_id, blue_5_champion_id, created_at FROM matches WHERE id = $1
+SELECT id, match_id, game_start, game_version, winning_team, queue_id, server_id, red_1_champion_id, red_2_champion_id, red_3_champion_id, red_4_champion_id, red_5_champion_id, blue_1_champion_id, blue_2_champion_id, blue_3_champion_id, blue_4_champion_id, blue_5_champion_id, created_at, average_rank, game_duration, outcome FROM matches WHERE id = $1
 `
 
 func (q *Queries) Match(ctx context.Context, id int32) (Match, error) {
@@ -189,6 +269,9 @@ func (q *Queries) Match(ctx context.Context, id int32) (Match, error) {
 		&i.Blue4ChampionID,
 		&i.Blue5ChampionID,
 		&i.CreatedAt,
+		&i.AverageRank,
+		&i.GameDuration,
+		&i.Outcome,
 	)
 	return i, err
 }
@@ -212,6 +295,120 @@ func (q *Queries) MatchAtPercentileID(ctx context.Context, dollar_1 int32) (int3
 	return id, err
 }
 
+const matchCountsByPatch = `-- name: MatchCountsByPatch :many
+SELECT server_id, (split_part(game_version, '.', 1) || '.' || split_part(game_version, '.', 2))::TEXT AS patch, COUNT(*) AS matches
+FROM matches
+GROUP BY server_id, patch
+ORDER BY server_id, patch
+`
+
+type MatchCountsByPatchRow struct {
+	ServerID string
+	Patch    string
+	Matches  int64
+}
+
+func (q *Queries) MatchCountsByPatch(ctx context.Context) ([]MatchCountsByPatchRow, error) {
+	rows, err := q.db.Query(ctx, matchCountsByPatch)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MatchCountsByPatchRow
+	for rows.Next() {
+		var i MatchCountsByPatchRow
+		if err := rows.Scan(&i.ServerID, &i.Patch, &i.Matches); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const matchCountsByQueue = `-- name: MatchCountsByQueue :many
+SELECT server_id, queue_id, COUNT(*) AS matches
+FROM matches
+GROUP BY server_id, queue_id
+ORDER BY server_id, matches DESC
+`
+
+type MatchCountsByQueueRow struct {
+	ServerID string
+	QueueID  int32
+	Matches  int64
+}
+
+func (q *Queries) MatchCountsByQueue(ctx context.Context) ([]MatchCountsByQueueRow, error) {
+	rows, err := q.db.Query(ctx, matchCountsByQueue)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MatchCountsByQueueRow
+	for rows.Next() {
+		var i MatchCountsByQueueRow
+		if err := rows.Scan(&i.ServerID, &i.QueueID, &i.Matches); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const matchCountsByServer = `-- name: MatchCountsByServer :many
+SELECT server_id,
+  COUNT(*) AS matches,
+  COUNT(*) FILTER (WHERE created_at >= CURRENT_TIMESTAMP - INTERVAL '1 hour') AS last_hour,
+  COUNT(*) FILTER (WHERE created_at >= CURRENT_TIMESTAMP - INTERVAL '1 day') AS last_day,
+  MIN(game_start)::TIMESTAMP AS oldest_game_start,
+  MAX(game_start)::TIMESTAMP AS newest_game_start
+FROM matches
+GROUP BY server_id
+ORDER BY server_id
+`
+
+type MatchCountsByServerRow struct {
+	ServerID        string
+	Matches         int64
+	LastHour        int64
+	LastDay         int64
+	OldestGameStart pgtype.Timestamp
+	NewestGameStart pgtype.Timestamp
+}
+
+func (q *Queries) MatchCountsByServer(ctx context.Context) ([]MatchCountsByServerRow, error) {
+	rows, err := q.db.Query(ctx, matchCountsByServer)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MatchCountsByServerRow
+	for rows.Next() {
+		var i MatchCountsByServerRow
+		if err := rows.Scan(
+			&i.ServerID,
+			&i.Matches,
+			&i.LastHour,
+			&i.LastDay,
+			&i.OldestGameStart,
+			&i.NewestGameStart,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const matchExists = `-- name: MatchExists :one
 SELECT EXISTS(SELECT 1 FROM matches WHERE match_id = $1)
 `
@@ -223,12 +420,70 @@ func (q *Queries) MatchExists(ctx context.Context, matchID string) (bool, error)
 	return exists, err
 }
 
+const matchIDsAfterID = `-- name: MatchIDsAfterID :many
+SELECT matches.id FROM matches WHERE id > $1
+  AND ($2::INTEGER IS NULL OR average_rank >= $2)
+  AND ($3::INTEGER IS NULL OR average_rank <= $3)
+  AND ($4::INTEGER IS NULL OR queue_id = $4)
+  AND outcome = 'completed'
+`
+
+type MatchIDsAfterIDParams struct {
+	ID      int32
+	MinRank pgtype.Int4
+	MaxRank pgtype.Int4
+	QueueID pgtype.Int4
+}
+
+func (q *Queries) MatchIDsAfterID(ctx context.Context, arg MatchIDsAfterIDParams) ([]int32, error) {
+	rows, err := q.db.Query(ctx, matchIDsAfterID,
+		arg.ID,
+		arg.MinRank,
+		arg.MaxRank,
+		arg.QueueID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const matchIDsUpToID = `-- name: MatchIDsUpToID :many
 SELECT matches.id FROM matches WHERE id <= $1
+  AND ($2::INTEGER IS NULL OR average_rank >= $2)
+  AND ($3::INTEGER IS NULL OR average_rank <= $3)
+  AND ($4::INTEGER IS NULL OR queue_id = $4)
+  AND (outcome = 'completed' OR ($5::BOOLEAN AND outcome = 'remake'))
 `
 
-func (q *Queries) MatchIDsUpToID(ctx context.Context, id int32) ([]int32, error) {
-	rows, err := q.db.Query(ctx, matchIDsUpToID, id)
+type MatchIDsUpToIDParams struct {
+	ID             int32
+	MinRank        pgtype.Int4
+	MaxRank        pgtype.Int4
+	QueueID        pgtype.Int4
+	IncludeRemakes bool
+}
+
+func (q *Queries) MatchIDsUpToID(ctx context.Context, arg MatchIDsUpToIDParams) ([]int32, error) {
+	rows, err := q.db.Query(ctx, matchIDsUpToID,
+		arg.ID,
+		arg.MinRank,
+		arg.MaxRank,
+		arg.QueueID,
+		arg.IncludeRemakes,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -247,6 +502,183 @@ func (q *Queries) MatchIDsUpToID(ctx context.Context, id int32) ([]int32, error)
 	return items, nil
 }
 
+const matchOutcomesUpToID = `-- name: MatchOutcomesUpToID :many
+SELECT outcome, COUNT(*) FROM matches WHERE id <= $1
+  AND ($2::INTEGER IS NULL OR average_rank >= $2)
+  AND ($3::INTEGER IS NULL OR average_rank <= $3)
+  AND ($4::INTEGER IS NULL OR queue_id = $4)
+GROUP BY outcome
+ORDER BY outcome
+`
+
+type MatchOutcomesUpToIDParams struct {
+	ID      int32
+	MinRank pgtype.Int4
+	MaxRank pgtype.Int4
+	QueueID pgtype.Int4
+}
+
+type MatchOutcomesUpToIDRow struct {
+	Outcome string
+	Count   int64
+}
+
+func (q *Queries) MatchOutcomesUpToID(ctx context.Context, arg MatchOutcomesUpToIDParams) ([]MatchOutcomesUpToIDRow, error) {
+	rows, err := q.db.Query(ctx, matchOutcomesUpToID,
+		arg.ID,
+		arg.MinRank,
+		arg.MaxRank,
+		arg.QueueID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MatchOutcomesUpToIDRow
+	for rows.Next() {
+		var i MatchOutcomesUpToIDRow
+		if err := rows.Scan(&i.Outcome, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const matchQueueIDs = `-- name: MatchQueueIDs :many
+SELECT DISTINCT queue_id FROM matches ORDER BY queue_id
+`
+
+func (q *Queries) MatchQueueIDs(ctx context.Context) ([]int32, error) {
+	rows, err := q.db.Query(ctx, matchQueueIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var queue_id int32
+		if err := rows.Scan(&queue_id); err != nil {
+			return nil, err
+		}
+		items = append(items, queue_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const matchesAfterID = `-- name: MatchesAfterID :many
+SELECT id, match_id, game_start, game_version, winning_team, queue_id, server_id, red_1_champion_id, red_2_champion_id, red_3_champion_id, red_4_champion_id, red_5_champion_id, blue_1_champion_id, blue_2_champion_id, blue_3_champion_id, blue_4_champion_id, blue_5_champion_id, created_at, average_rank, game_duration, outcome FROM matches WHERE id > $1 ORDER BY id LIMIT $2
+`
+
+type MatchesAfterIDParams struct {
+	ID    int32
+	Limit int32
+}
+
+func (q *Queries) MatchesAfterID(ctx context.Context, arg MatchesAfterIDParams) ([]Match, error) {
+	rows, err := q.db.Query(ctx, matchesAfterID, arg.ID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Match
+	for rows.Next() {
+		var i Match
+		if err := rows.Scan(
+			&i.ID,
+			&i.MatchID,
+			&i.GameStart,
+			&i.GameVersion,
+			&i.WinningTeam,
+			&i.QueueID,
+			&i.ServerID,
+			&i.Red1ChampionID,
+			&i.Red2ChampionID,
+			&i.Red3ChampionID,
+			&i.Red4ChampionID,
+			&i.Red5ChampionID,
+			&i.Blue1ChampionID,
+			&i.Blue2ChampionID,
+			&i.Blue3ChampionID,
+			&i.Blue4ChampionID,
+			&i.Blue5ChampionID,
+			&i.CreatedAt,
+			&i.AverageRank,
+			&i.GameDuration,
+			&i.Outcome,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const matchesInScope = `-- name: MatchesInScope :many
+SELECT id, match_id, game_start, game_version, winning_team, queue_id, server_id, red_1_champion_id, red_2_champion_id, red_3_champion_id, red_4_champion_id, red_5_champion_id, blue_1_champion_id, blue_2_champion_id, blue_3_champion_id, blue_4_champion_id, blue_5_champion_id, created_at, average_rank, game_duration, outcome FROM matches
+WHERE ($1::TEXT IS NULL OR server_id = $1)
+  AND ($2::TEXT IS NULL OR game_version LIKE $2 || '.%')
+  AND ($3::TIMESTAMP IS NULL OR game_start < $3)
+ORDER BY id
+`
+
+type MatchesInScopeParams struct {
+	ServerID pgtype.Text
+	Patch    pgtype.Text
+	Before   pgtype.Timestamp
+}
+
+func (q *Queries) MatchesInScope(ctx context.Context, arg MatchesInScopeParams) ([]Match, error) {
+	rows, err := q.db.Query(ctx, matchesInScope, arg.ServerID, arg.Patch, arg.Before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Match
+	for rows.Next() {
+		var i Match
+		if err := rows.Scan(
+			&i.ID,
+			&i.MatchID,
+			&i.GameStart,
+			&i.GameVersion,
+			&i.WinningTeam,
+			&i.QueueID,
+			&i.ServerID,
+			&i.Red1ChampionID,
+			&i.Red2ChampionID,
+			&i.Red3ChampionID,
+			&i.Red4ChampionID,
+			&i.Red5ChampionID,
+			&i.Blue1ChampionID,
+			&i.Blue2ChampionID,
+			&i.Blue3ChampionID,
+			&i.Blue4ChampionID,
+			&i.Blue5ChampionID,
+			&i.CreatedAt,
+			&i.AverageRank,
+			&i.GameDuration,
+			&i.Outcome,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const randomMatchIDFromServer = `-- name: RandomMatchIDFromServer :one
 SELECT matches.match_id FROM matches WHERE server_id = $1 ORDER BY RANDOM() LIMIT 1
 `
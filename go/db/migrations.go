@@ -0,0 +1,8 @@
+package db
+
+import "embed"
+
+// Numbered up and down migrations, applied by internal/migrate. sqlc reads the up migrations as the schema.
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
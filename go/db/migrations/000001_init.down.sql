@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS champion_stats;
+DROP TABLE IF EXISTS player_search_log;
+DROP TABLE IF EXISTS champions;
+DROP TABLE IF EXISTS matches;
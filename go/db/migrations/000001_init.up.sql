@@ -1,3 +1,4 @@
+-- IF NOT EXISTS so databases created from the old schema.sql can adopt migrations
 CREATE TABLE IF NOT EXISTS matches (
   id SERIAL PRIMARY KEY,
   match_id VARCHAR(255) NOT NULL UNIQUE,
@@ -41,4 +42,4 @@ CREATE TABLE IF NOT EXISTS champion_stats (
 );
 
 CREATE INDEX IF NOT EXISTS idx_match_id ON matches(match_id);
-CREATE INDEX IF NOT EXISTS idx_match_server_id ON matches(server_id);
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS idx_match_server_id ON matches(server_id);
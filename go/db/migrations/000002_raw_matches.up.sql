@@ -0,0 +1,23 @@
+-- The full match-v5 response for every crawled match, gzip compressed, so new fields can be
+-- extracted later by reprocessing instead of crawling again
+CREATE TABLE raw_matches (
+  match_id VARCHAR(255) PRIMARY KEY,
+  data BYTEA NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE participants (
+  id SERIAL PRIMARY KEY,
+  match_id VARCHAR(255) NOT NULL REFERENCES matches(match_id) ON DELETE CASCADE,
+  puuid VARCHAR(255) NOT NULL,
+  team_id INTEGER NOT NULL,
+  champion_id INTEGER NOT NULL,
+  team_position VARCHAR(255) NOT NULL,
+  win BOOLEAN NOT NULL,
+  kills INTEGER NOT NULL,
+  deaths INTEGER NOT NULL,
+  assists INTEGER NOT NULL,
+  UNIQUE (match_id, puuid)
+);
+
+CREATE INDEX idx_participants_puuid ON participants(puuid);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS match_objectives;
+DROP TABLE IF EXISTS match_timeline_frames;
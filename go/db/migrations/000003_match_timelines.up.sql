@@ -0,0 +1,17 @@
+-- Blue minus red totals at the end of every minute, from the match timeline
+CREATE TABLE match_timeline_frames (
+  match_id VARCHAR(255) NOT NULL REFERENCES matches(match_id) ON DELETE CASCADE,
+  minute INTEGER NOT NULL,
+  gold_diff INTEGER NOT NULL,
+  xp_diff INTEGER NOT NULL,
+  PRIMARY KEY (match_id, minute)
+);
+
+-- Which team took each first objective (first_blood, first_tower, first_dragon, ...) and when
+CREATE TABLE match_objectives (
+  match_id VARCHAR(255) NOT NULL REFERENCES matches(match_id) ON DELETE CASCADE,
+  objective VARCHAR(255) NOT NULL,
+  team_id INTEGER NOT NULL,
+  timestamp_ms INTEGER NOT NULL,
+  PRIMARY KEY (match_id, objective)
+);
@@ -0,0 +1,5 @@
+ALTER TABLE champion_stats DROP COLUMN IF EXISTS max_rank;
+ALTER TABLE champion_stats DROP COLUMN IF EXISTS min_rank;
+DROP INDEX IF EXISTS idx_match_average_rank;
+ALTER TABLE matches DROP COLUMN IF EXISTS average_rank;
+DROP TABLE IF EXISTS player_ranks;
@@ -0,0 +1,19 @@
+-- Solo queue rank of crawled players, refetched once it is older than crawler.rank_ttl.
+-- rank_score orders ranks (see internal/rank) and is NULL for unranked players.
+CREATE TABLE player_ranks (
+  puuid VARCHAR(255) PRIMARY KEY,
+  server_id VARCHAR(255) NOT NULL,
+  tier VARCHAR(255) NOT NULL,
+  division VARCHAR(255) NOT NULL,
+  league_points INTEGER NOT NULL,
+  rank_score INTEGER,
+  fetched_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Average rank_score of the ranked participants, NULL when none were ranked or ranks weren't fetched
+ALTER TABLE matches ADD COLUMN average_rank INTEGER;
+CREATE INDEX idx_match_average_rank ON matches(average_rank);
+
+-- The rank bracket a snapshot was built from, NULL for no limit
+ALTER TABLE champion_stats ADD COLUMN min_rank INTEGER;
+ALTER TABLE champion_stats ADD COLUMN max_rank INTEGER;
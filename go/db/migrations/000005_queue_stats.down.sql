@@ -0,0 +1,2 @@
+ALTER TABLE champion_stats DROP COLUMN IF EXISTS queue_id;
+DROP INDEX IF EXISTS idx_match_queue_id;
@@ -0,0 +1,4 @@
+CREATE INDEX idx_match_queue_id ON matches(queue_id);
+
+-- The queue a snapshot was built from, NULL for every queue
+ALTER TABLE champion_stats ADD COLUMN queue_id INTEGER;
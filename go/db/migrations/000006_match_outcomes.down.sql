@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_match_outcome;
+ALTER TABLE matches DROP COLUMN IF EXISTS outcome;
+ALTER TABLE matches DROP COLUMN IF EXISTS game_duration;
@@ -0,0 +1,8 @@
+-- Length of the match in seconds, NULL for matches crawled before it was saved
+ALTER TABLE matches ADD COLUMN game_duration INTEGER;
+
+-- completed, remake (ended by an early surrender vote or shorter than a remake) or aborted (ended without a winner).
+-- Matches crawled before this was saved are completed until they are reprocessed.
+ALTER TABLE matches ADD COLUMN outcome VARCHAR(255) NOT NULL DEFAULT 'completed'
+  CHECK (outcome IN ('completed', 'remake', 'aborted'));
+CREATE INDEX idx_match_outcome ON matches(outcome);
@@ -0,0 +1,13 @@
+-- How far back a player's history in a queue has been backfilled. Pages are counted from end_time,
+-- which is fixed when the backfill starts so new matches don't shift them. A cursor whose start_time
+-- is not the configured bound is started over.
+CREATE TABLE backfill_cursors (
+  puuid VARCHAR(255) NOT NULL,
+  queue_id INTEGER NOT NULL,
+  start_time TIMESTAMP NOT NULL,
+  end_time TIMESTAMP NOT NULL,
+  next_start INTEGER NOT NULL DEFAULT 0,
+  done BOOLEAN NOT NULL DEFAULT FALSE,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (puuid, queue_id)
+);
@@ -0,0 +1,16 @@
+-- When each player may be crawled again. interval_seconds is the wait that was picked after the last crawl
+-- and new_matches how many new matches that crawl found, the next wait is worked out from them.
+CREATE TABLE player_schedule (
+  puuid VARCHAR(255) PRIMARY KEY,
+  last_crawled_at TIMESTAMP NOT NULL,
+  next_crawl_at TIMESTAMP NOT NULL,
+  interval_seconds INTEGER NOT NULL,
+  new_matches INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX idx_player_schedule_next_crawl_at ON player_schedule(next_crawl_at);
+
+-- Players crawled before scheduling get the old fixed 48 hour wait once
+INSERT INTO player_schedule (puuid, last_crawled_at, next_crawl_at, interval_seconds)
+SELECT player_id, MAX(search_time), MAX(search_time) + INTERVAL '48 hours', 172800
+FROM player_search_log
+GROUP BY player_id;
@@ -0,0 +1,9 @@
+-- Matches that failed matches validate, moved out of matches so stats builds never see them.
+-- data is the matches row as it was, reasons the checks it failed. Participants and timelines are deleted
+-- with the match, the raw match stays archived.
+CREATE TABLE quarantined_matches (
+  match_id VARCHAR(255) PRIMARY KEY,
+  reasons TEXT[] NOT NULL,
+  data JSONB NOT NULL,
+  quarantined_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
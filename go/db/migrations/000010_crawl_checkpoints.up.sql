@@ -0,0 +1,12 @@
+-- A player's crawl that hasn't finished: the recent match ids that were listed for them and the ones still
+-- to fetch. Saved before the matches are fetched and deleted once the player is scheduled again, so a crawl
+-- cut short by a shutdown is resumed by the region's crawler when it starts.
+CREATE TABLE crawl_checkpoints (
+  puuid VARCHAR(255) PRIMARY KEY,
+  region VARCHAR(255) NOT NULL,
+  match_ids TEXT[] NOT NULL,
+  remaining TEXT[] NOT NULL,
+  new_matches INTEGER NOT NULL DEFAULT 0,
+  updated_at TIMESTAMP NOT NULL
+);
+CREATE INDEX idx_crawl_checkpoints_region ON crawl_checkpoints(region);
@@ -8,6 +8,16 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type BackfillCursor struct {
+	Puuid     string
+	QueueID   int32
+	StartTime pgtype.Timestamp
+	EndTime   pgtype.Timestamp
+	NextStart int32
+	Done      bool
+	UpdatedAt pgtype.Timestamp
+}
+
 type Champion struct {
 	ID        int32
 	Name      string
@@ -20,6 +30,18 @@ type ChampionStat struct {
 	Data        []byte
 	LastMatchID int32
 	CreatedAt   pgtype.Timestamp
+	MinRank     pgtype.Int4
+	MaxRank     pgtype.Int4
+	QueueID     pgtype.Int4
+}
+
+type CrawlCheckpoint struct {
+	Puuid      string
+	Region     string
+	MatchIds   []string
+	Remaining  []string
+	NewMatches int32
+	UpdatedAt  pgtype.Timestamp
 }
 
 type Match struct {
@@ -41,6 +63,54 @@ type Match struct {
 	Blue4ChampionID int32
 	Blue5ChampionID int32
 	CreatedAt       pgtype.Timestamp
+	AverageRank     pgtype.Int4
+	GameDuration    pgtype.Int4
+	Outcome         string
+}
+
+type MatchObjective struct {
+	MatchID     string
+	Objective   string
+	TeamID      int32
+	TimestampMs int32
+}
+
+type MatchTimelineFrame struct {
+	MatchID  string
+	Minute   int32
+	GoldDiff int32
+	XpDiff   int32
+}
+
+type Participant struct {
+	ID           int32
+	MatchID      string
+	Puuid        string
+	TeamID       int32
+	ChampionID   int32
+	TeamPosition string
+	Win          bool
+	Kills        int32
+	Deaths       int32
+	Assists      int32
+}
+
+type PlayerRank struct {
+	Puuid        string
+	ServerID     string
+	Tier         string
+	Division     string
+	LeaguePoints int32
+	RankScore    pgtype.Int4
+	FetchedAt    pgtype.Timestamp
+}
+
+type PlayerSchedule struct {
+	Puuid           string
+	LastCrawledAt   pgtype.Timestamp
+	NextCrawlAt     pgtype.Timestamp
+	IntervalSeconds int32
+	NewMatches      int32
 }
 
 type PlayerSearchLog struct {
@@ -49,3 +119,16 @@ type PlayerSearchLog struct {
 	SearchTime pgtype.Timestamp
 	CreatedAt  pgtype.Timestamp
 }
+
+type QuarantinedMatch struct {
+	MatchID       string
+	Reasons       []string
+	Data          []byte
+	QuarantinedAt pgtype.Timestamp
+}
+
+type RawMatch struct {
+	MatchID   string
+	Data      []byte
+	CreatedAt pgtype.Timestamp
+}
@@ -11,6 +11,42 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countPlayerSearchesBefore = `-- name: CountPlayerSearchesBefore :one
+SELECT COUNT(*) FROM player_search_log
+WHERE $1::TIMESTAMP IS NULL OR search_time < $1
+`
+
+func (q *Queries) CountPlayerSearchesBefore(ctx context.Context, before pgtype.Timestamp) (int64, error) {
+	row := q.db.QueryRow(ctx, countPlayerSearchesBefore, before)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSearchedPlayers = `-- name: CountSearchedPlayers :one
+SELECT COUNT(DISTINCT player_id) FROM player_search_log
+`
+
+func (q *Queries) CountSearchedPlayers(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, countSearchedPlayers)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deletePlayerSearchesBefore = `-- name: DeletePlayerSearchesBefore :execrows
+DELETE FROM player_search_log
+WHERE $1::TIMESTAMP IS NULL OR search_time < $1
+`
+
+func (q *Queries) DeletePlayerSearchesBefore(ctx context.Context, before pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, deletePlayerSearchesBefore, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const lastSearched = `-- name: LastSearched :one
 SELECT search_time FROM player_search_log WHERE player_id = $1 ORDER BY search_time DESC LIMIT 1
 `
@@ -41,3 +77,34 @@ func (q *Queries) PlayerHasBeenSearched(ctx context.Context, playerID string) (b
 	err := row.Scan(&exists)
 	return exists, err
 }
+
+const playerSearchesBefore = `-- name: PlayerSearchesBefore :many
+SELECT id, player_id, search_time, created_at FROM player_search_log
+WHERE $1::TIMESTAMP IS NULL OR search_time < $1
+ORDER BY id
+`
+
+func (q *Queries) PlayerSearchesBefore(ctx context.Context, before pgtype.Timestamp) ([]PlayerSearchLog, error) {
+	rows, err := q.db.Query(ctx, playerSearchesBefore, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PlayerSearchLog
+	for rows.Next() {
+		var i PlayerSearchLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.PlayerID,
+			&i.SearchTime,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
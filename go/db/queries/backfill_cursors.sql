@@ -0,0 +1,25 @@
+-- name: BackfillCursor :one
+SELECT * FROM backfill_cursors WHERE puuid = $1 AND queue_id = $2;
+
+-- name: UpsertBackfillCursor :exec
+INSERT INTO backfill_cursors (puuid, queue_id, start_time, end_time, next_start, done, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP)
+ON CONFLICT (puuid, queue_id) DO UPDATE SET
+  start_time = EXCLUDED.start_time,
+  end_time = EXCLUDED.end_time,
+  next_start = EXCLUDED.next_start,
+  done = EXCLUDED.done,
+  updated_at = EXCLUDED.updated_at;
+
+-- name: CountBackfillCursorsBefore :one
+SELECT COUNT(*) FROM backfill_cursors
+WHERE sqlc.narg('before')::TIMESTAMP IS NULL OR updated_at < sqlc.narg('before');
+
+-- name: BackfillCursorsBefore :many
+SELECT * FROM backfill_cursors
+WHERE sqlc.narg('before')::TIMESTAMP IS NULL OR updated_at < sqlc.narg('before')
+ORDER BY puuid, queue_id;
+
+-- name: DeleteBackfillCursorsBefore :execrows
+DELETE FROM backfill_cursors
+WHERE sqlc.narg('before')::TIMESTAMP IS NULL OR updated_at < sqlc.narg('before');
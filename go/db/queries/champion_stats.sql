@@ -1,8 +1,36 @@
 -- name: CreateChampionStats :exec
 INSERT INTO champion_stats (
   data,
-  last_match_id
-) VALUES ($1, $2);
+  last_match_id,
+  min_rank,
+  max_rank,
+  queue_id
+) VALUES ($1, $2, $3, $4, $5);
 
 -- name: LastChampionStats :one
-SELECT * FROM champion_stats ORDER BY created_at DESC LIMIT 1;
+-- ARAM (queue 450) snapshots are left out, they are only for recommend -aram
+SELECT * FROM champion_stats WHERE queue_id IS DISTINCT FROM 450 ORDER BY created_at DESC LIMIT 1;
+
+-- name: LastChampionStatsForBracket :one
+-- Only snapshots built for exactly this bracket, without a queue ARAM snapshots are left out like in LastChampionStats
+SELECT * FROM champion_stats
+WHERE min_rank IS NOT DISTINCT FROM sqlc.narg('min_rank')::INTEGER
+  AND max_rank IS NOT DISTINCT FROM sqlc.narg('max_rank')::INTEGER
+  AND ((sqlc.narg('queue_id')::INTEGER IS NULL AND queue_id IS DISTINCT FROM 450) OR queue_id = sqlc.narg('queue_id'))
+ORDER BY created_at DESC LIMIT 1;
+
+-- name: LastChampionStatsForQueue :one
+SELECT * FROM champion_stats WHERE queue_id = $1 ORDER BY created_at DESC LIMIT 1;
+
+-- name: CountChampionStatsBefore :one
+SELECT COUNT(*) FROM champion_stats
+WHERE sqlc.narg('before')::TIMESTAMP IS NULL OR created_at < sqlc.narg('before');
+
+-- name: ChampionStatsBefore :many
+SELECT * FROM champion_stats
+WHERE sqlc.narg('before')::TIMESTAMP IS NULL OR created_at < sqlc.narg('before')
+ORDER BY id;
+
+-- name: DeleteChampionStatsBefore :execrows
+DELETE FROM champion_stats
+WHERE sqlc.narg('before')::TIMESTAMP IS NULL OR created_at < sqlc.narg('before');
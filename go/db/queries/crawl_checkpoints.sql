@@ -0,0 +1,19 @@
+-- name: CrawlCheckpointsForRegion :many
+SELECT * FROM crawl_checkpoints WHERE region = $1 ORDER BY updated_at;
+
+-- name: UpsertCrawlCheckpoint :exec
+INSERT INTO crawl_checkpoints (puuid, region, match_ids, remaining, new_matches, updated_at)
+VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
+ON CONFLICT (puuid) DO UPDATE SET
+  region = EXCLUDED.region,
+  match_ids = EXCLUDED.match_ids,
+  remaining = EXCLUDED.remaining,
+  new_matches = EXCLUDED.new_matches,
+  updated_at = EXCLUDED.updated_at;
+
+-- name: DeleteCrawlCheckpoint :exec
+DELETE FROM crawl_checkpoints WHERE puuid = $1;
+
+-- name: DeleteCrawlCheckpointsBefore :execrows
+DELETE FROM crawl_checkpoints
+WHERE sqlc.narg('before')::TIMESTAMP IS NULL OR updated_at < sqlc.narg('before');
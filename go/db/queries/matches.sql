@@ -15,9 +15,12 @@ INSERT INTO matches (
     red_2_champion_id, 
     red_3_champion_id, 
     red_4_champion_id, 
-    red_5_champion_id
+    red_5_champion_id,
+    average_rank,
+    game_duration,
+    outcome
   ) 
-  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16) RETURNING id;
+  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19) RETURNING id;
 
 -- name: LastMatchesFromServer :many
 SELECT matches.match_id FROM matches WHERE server_id = $1 ORDER BY created_at DESC LIMIT 10;
@@ -46,10 +49,83 @@ ORDER BY id DESC
 LIMIT 1;
 
 -- name: MatchIDsUpToID :many
-SELECT matches.id FROM matches WHERE id <= $1;
+SELECT matches.id FROM matches WHERE id <= $1
+  AND (sqlc.narg('min_rank')::INTEGER IS NULL OR average_rank >= sqlc.narg('min_rank'))
+  AND (sqlc.narg('max_rank')::INTEGER IS NULL OR average_rank <= sqlc.narg('max_rank'))
+  AND (sqlc.narg('queue_id')::INTEGER IS NULL OR queue_id = sqlc.narg('queue_id'))
+  AND (outcome = 'completed' OR (@include_remakes::BOOLEAN AND outcome = 'remake'));
+
+-- name: MatchIDsAfterID :many
+SELECT matches.id FROM matches WHERE id > $1
+  AND (sqlc.narg('min_rank')::INTEGER IS NULL OR average_rank >= sqlc.narg('min_rank'))
+  AND (sqlc.narg('max_rank')::INTEGER IS NULL OR average_rank <= sqlc.narg('max_rank'))
+  AND (sqlc.narg('queue_id')::INTEGER IS NULL OR queue_id = sqlc.narg('queue_id'))
+  AND outcome = 'completed';
+
+-- name: MatchOutcomesUpToID :many
+SELECT outcome, COUNT(*) FROM matches WHERE id <= $1
+  AND (sqlc.narg('min_rank')::INTEGER IS NULL OR average_rank >= sqlc.narg('min_rank'))
+  AND (sqlc.narg('max_rank')::INTEGER IS NULL OR average_rank <= sqlc.narg('max_rank'))
+  AND (sqlc.narg('queue_id')::INTEGER IS NULL OR queue_id = sqlc.narg('queue_id'))
+GROUP BY outcome
+ORDER BY outcome;
 
 -- name: GameVersions :many
 SELECT DISTINCT game_version FROM matches;
 
 -- name: RandomMatchIDFromServer :one
 SELECT matches.match_id FROM matches WHERE server_id = $1 ORDER BY RANDOM() LIMIT 1;
+
+-- name: CountMatchesInScope :one
+SELECT COUNT(*) FROM matches
+WHERE (sqlc.narg('server_id')::TEXT IS NULL OR server_id = sqlc.narg('server_id'))
+  AND (sqlc.narg('patch')::TEXT IS NULL OR game_version LIKE sqlc.narg('patch') || '.%')
+  AND (sqlc.narg('before')::TIMESTAMP IS NULL OR game_start < sqlc.narg('before'));
+
+-- name: MatchesInScope :many
+SELECT * FROM matches
+WHERE (sqlc.narg('server_id')::TEXT IS NULL OR server_id = sqlc.narg('server_id'))
+  AND (sqlc.narg('patch')::TEXT IS NULL OR game_version LIKE sqlc.narg('patch') || '.%')
+  AND (sqlc.narg('before')::TIMESTAMP IS NULL OR game_start < sqlc.narg('before'))
+ORDER BY id;
+
+-- name: DeleteMatchesInScope :execrows
+DELETE FROM matches
+WHERE (sqlc.narg('server_id')::TEXT IS NULL OR server_id = sqlc.narg('server_id'))
+  AND (sqlc.narg('patch')::TEXT IS NULL OR game_version LIKE sqlc.narg('patch') || '.%')
+  AND (sqlc.narg('before')::TIMESTAMP IS NULL OR game_start < sqlc.narg('before'));
+
+-- name: ExistingMatchIDs :many
+-- Quarantined matches count as existing so they aren't crawled again
+SELECT match_id FROM matches WHERE match_id = ANY(@match_ids::TEXT[])
+UNION ALL
+SELECT match_id FROM quarantined_matches WHERE match_id = ANY(@match_ids::TEXT[]);
+
+-- name: MatchCountsByServer :many
+SELECT server_id,
+  COUNT(*) AS matches,
+  COUNT(*) FILTER (WHERE created_at >= CURRENT_TIMESTAMP - INTERVAL '1 hour') AS last_hour,
+  COUNT(*) FILTER (WHERE created_at >= CURRENT_TIMESTAMP - INTERVAL '1 day') AS last_day,
+  MIN(game_start)::TIMESTAMP AS oldest_game_start,
+  MAX(game_start)::TIMESTAMP AS newest_game_start
+FROM matches
+GROUP BY server_id
+ORDER BY server_id;
+
+-- name: MatchCountsByPatch :many
+SELECT server_id, (split_part(game_version, '.', 1) || '.' || split_part(game_version, '.', 2))::TEXT AS patch, COUNT(*) AS matches
+FROM matches
+GROUP BY server_id, patch
+ORDER BY server_id, patch;
+
+-- name: MatchCountsByQueue :many
+SELECT server_id, queue_id, COUNT(*) AS matches
+FROM matches
+GROUP BY server_id, queue_id
+ORDER BY server_id, matches DESC;
+
+-- name: MatchQueueIDs :many
+SELECT DISTINCT queue_id FROM matches ORDER BY queue_id;
+
+-- name: MatchesAfterID :many
+SELECT * FROM matches WHERE id > $1 ORDER BY id LIMIT $2;
@@ -5,4 +5,20 @@ INSERT INTO player_search_log (player_id) VALUES ($1) RETURNING id;
 SELECT EXISTS(SELECT 1 FROM player_search_log WHERE player_id = $1);
 
 -- name: LastSearched :one
-SELECT search_time FROM player_search_log WHERE player_id = $1 ORDER BY search_time DESC LIMIT 1;
\ No newline at end of file
+SELECT search_time FROM player_search_log WHERE player_id = $1 ORDER BY search_time DESC LIMIT 1;
+
+-- name: CountPlayerSearchesBefore :one
+SELECT COUNT(*) FROM player_search_log
+WHERE sqlc.narg('before')::TIMESTAMP IS NULL OR search_time < sqlc.narg('before');
+
+-- name: PlayerSearchesBefore :many
+SELECT * FROM player_search_log
+WHERE sqlc.narg('before')::TIMESTAMP IS NULL OR search_time < sqlc.narg('before')
+ORDER BY id;
+
+-- name: DeletePlayerSearchesBefore :execrows
+DELETE FROM player_search_log
+WHERE sqlc.narg('before')::TIMESTAMP IS NULL OR search_time < sqlc.narg('before');
+
+-- name: CountSearchedPlayers :one
+SELECT COUNT(DISTINCT player_id) FROM player_search_log;
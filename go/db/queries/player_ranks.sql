@@ -0,0 +1,26 @@
+-- name: PlayerRank :one
+SELECT * FROM player_ranks WHERE puuid = $1;
+
+-- name: UpsertPlayerRank :exec
+INSERT INTO player_ranks (puuid, server_id, tier, division, league_points, rank_score, fetched_at)
+VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP)
+ON CONFLICT (puuid) DO UPDATE SET
+  server_id = EXCLUDED.server_id,
+  tier = EXCLUDED.tier,
+  division = EXCLUDED.division,
+  league_points = EXCLUDED.league_points,
+  rank_score = EXCLUDED.rank_score,
+  fetched_at = EXCLUDED.fetched_at;
+
+-- name: CountPlayerRanksBefore :one
+SELECT COUNT(*) FROM player_ranks
+WHERE sqlc.narg('before')::TIMESTAMP IS NULL OR fetched_at < sqlc.narg('before');
+
+-- name: PlayerRanksBefore :many
+SELECT * FROM player_ranks
+WHERE sqlc.narg('before')::TIMESTAMP IS NULL OR fetched_at < sqlc.narg('before')
+ORDER BY puuid;
+
+-- name: DeletePlayerRanksBefore :execrows
+DELETE FROM player_ranks
+WHERE sqlc.narg('before')::TIMESTAMP IS NULL OR fetched_at < sqlc.narg('before');
@@ -0,0 +1,18 @@
+-- name: PlayerSchedule :one
+SELECT * FROM player_schedule WHERE puuid = $1;
+
+-- name: UpsertPlayerSchedule :exec
+INSERT INTO player_schedule (puuid, last_crawled_at, next_crawl_at, interval_seconds, new_matches)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (puuid) DO UPDATE SET
+  last_crawled_at = EXCLUDED.last_crawled_at,
+  next_crawl_at = EXCLUDED.next_crawl_at,
+  interval_seconds = EXCLUDED.interval_seconds,
+  new_matches = EXCLUDED.new_matches;
+
+-- name: DeletePlayerSchedulesBefore :execrows
+DELETE FROM player_schedule
+WHERE sqlc.narg('before')::TIMESTAMP IS NULL OR last_crawled_at < sqlc.narg('before');
+
+-- name: CountDuePlayers :one
+SELECT COUNT(*) FROM player_schedule WHERE next_crawl_at <= $1;
@@ -0,0 +1,20 @@
+-- name: QuarantineMatch :execrows
+-- Moves a match into quarantined_matches, its participants and timeline are deleted with it
+WITH moved AS (
+  DELETE FROM matches WHERE matches.match_id = @match_id RETURNING *
+)
+INSERT INTO quarantined_matches (match_id, reasons, data)
+SELECT moved.match_id, @reasons::TEXT[], to_jsonb(moved) FROM moved
+ON CONFLICT (match_id) DO UPDATE SET
+  reasons = EXCLUDED.reasons,
+  data = EXCLUDED.data,
+  quarantined_at = CURRENT_TIMESTAMP;
+
+-- name: QuarantineRejectedMatch :exec
+-- Keeps a match the database wouldn't take out of the crawl, data is what the crawler tried to save
+INSERT INTO quarantined_matches (match_id, reasons, data)
+VALUES ($1, $2, $3)
+ON CONFLICT (match_id) DO UPDATE SET
+  reasons = EXCLUDED.reasons,
+  data = EXCLUDED.data,
+  quarantined_at = CURRENT_TIMESTAMP;
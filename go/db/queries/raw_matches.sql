@@ -0,0 +1,8 @@
+-- name: RawMatchesAfter :many
+-- Quarantined matches are left out so reprocessing doesn't bring them back
+SELECT * FROM raw_matches WHERE match_id > $1
+  AND match_id NOT IN (SELECT match_id FROM quarantined_matches)
+ORDER BY match_id LIMIT $2;
+
+-- name: CountRawMatches :one
+SELECT COUNT(*) FROM raw_matches;
@@ -2,13 +2,21 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"lol-champ-recommender/internal/logging"
+	"lol-champ-recommender/internal/metrics"
+
 	"golang.org/x/time/rate"
 )
 
@@ -17,8 +25,10 @@ const (
 )
 
 type RiotClient struct {
-	apiKey     string
-	Region     string
+	apiKey string
+	Region string
+	// Format string for the API host, the routing value is substituted in. Can be pointed at a local fake server.
+	BaseURL    string
 	client     *http.Client
 	limiter    *rate.Limiter
 	ctx        context.Context
@@ -30,10 +40,13 @@ func NewRiotClient(apiKey, region string, ctx context.Context) (*RiotClient, err
 	if apiKey == "" {
 		return nil, fmt.Errorf("API key is required")
 	}
+	// The key is only sent as a header, but make sure it never ends up in the logs
+	logging.Redact(apiKey)
 	return &RiotClient{
-		apiKey: apiKey,
-		Region: region,
-		ctx:    ctx,
+		apiKey:  apiKey,
+		Region:  region,
+		BaseURL: baseURL,
+		ctx:     ctx,
 		client: &http.Client{
 			Timeout: time.Second * 10,
 		},
@@ -41,21 +54,35 @@ func NewRiotClient(apiKey, region string, ctx context.Context) (*RiotClient, err
 	}, nil
 }
 
-func (c *RiotClient) request(url string) ([]byte, error) {
+// SetRateLimit replaces the client's request rate, e.g. for a key with higher limits or a fake server in tests
+func (c *RiotClient) SetRateLimit(limit rate.Limit, burst int) {
+	c.limiter.SetLimit(limit)
+	c.limiter.SetBurst(burst)
+}
+
+// request GETs url, endpoint names the API for metrics, e.g. match-v5/matches
+func (c *RiotClient) request(endpoint, url string) ([]byte, error) {
+	waitStart := time.Now()
 	c.mu.Lock()
 	if time.Now().Before(c.retryAfter) {
 		sleepDur := time.Until(c.retryAfter)
 		c.mu.Unlock()
-		time.Sleep(sleepDur)
+		// Stops waiting on shutdown so crawlers can save where they were
+		select {
+		case <-time.After(sleepDur):
+		case <-c.ctx.Done():
+		}
 	} else {
 		c.mu.Unlock()
 	}
 
-	if err := c.limiter.Wait(c.ctx); err != nil {
+	err := c.limiter.Wait(c.ctx)
+	metrics.LimiterWait.WithLabelValues(c.Region).Add(time.Since(waitStart).Seconds())
+	if err != nil {
 		return nil, fmt.Errorf("rate limiter error: %w", err)
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(c.ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
@@ -64,9 +91,12 @@ func (c *RiotClient) request(url string) ([]byte, error) {
 
 	resp, err := c.client.Do(req)
 	if err != nil {
+		metrics.Requests.WithLabelValues(c.Region, endpoint, "error").Inc()
 		return nil, fmt.Errorf("error making request: %w", err)
 	}
 	defer resp.Body.Close()
+	metrics.Requests.WithLabelValues(c.Region, endpoint, strconv.Itoa(resp.StatusCode)).Inc()
+	slog.Debug("Riot API request", "region", c.Region, "endpoint", endpoint, "status", resp.StatusCode)
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -74,6 +104,7 @@ func (c *RiotClient) request(url string) ([]byte, error) {
 	}
 
 	if resp.StatusCode == 429 {
+		metrics.RateLimited.WithLabelValues(c.Region).Inc()
 		c.mu.Lock()
 		if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
 			if seconds, err := strconv.Atoi(retryAfter); err == nil {
@@ -82,8 +113,10 @@ func (c *RiotClient) request(url string) ([]byte, error) {
 		} else {
 			c.retryAfter = time.Now().Add(10 * time.Second)
 		}
+		retryAfter := c.retryAfter
 		c.mu.Unlock()
-		return nil, fmt.Errorf("rate limited, retry after: %s", c.retryAfter)
+		slog.Warn("Rate limited", "region", c.Region, "endpoint", endpoint, "retry_after", retryAfter)
+		return nil, fmt.Errorf("rate limited, retry after: %s", retryAfter)
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -93,12 +126,75 @@ func (c *RiotClient) request(url string) ([]byte, error) {
 	return body, nil
 }
 
-func (c *RiotClient) RecentMatches(puuid string, count int) ([]byte, error) {
-	match_type := "ranked"
-	url := fmt.Sprintf("%s/lol/match/v5/matches/by-puuid/%s/ids?count=%d&type=%s",
-		fmt.Sprintf(baseURL, c.Region), puuid, count, match_type)
+// Account-v1 and match-v5 are routed by region (americas, asia, ...)
+func (c *RiotClient) regionalURL() string {
+	return fmt.Sprintf(c.BaseURL, c.Region)
+}
+
+// Champion-mastery-v4 and league-v4 are routed by platform, which is the server id stored on matches (NA1, EUW1, ...)
+func (c *RiotClient) platformURL(server string) string {
+	return fmt.Sprintf(c.BaseURL, strings.ToLower(server))
+}
+
+// Queues are the 5v5 queues matches can be crawled from, by match-v5 queue id
+var Queues = map[int]string{
+	400: "normal draft",
+	420: "ranked solo/duo",
+	430: "normal blind",
+	440: "ranked flex",
+	450: "ARAM",
+	490: "quickplay",
+}
+
+// QueueIDs lists the ids of Queues in order
+func QueueIDs() []int {
+	ids := make([]int, 0, len(Queues))
+	for id := range Queues {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// MatchQuery filters the match ids RecentMatches returns. Zero values are left out of the request.
+type MatchQuery struct {
+	// At most 100
+	Count int
+	// Index of the first id to return, counting back from the newest match
+	Start int
+	// Match-v5 queue id, e.g. 420
+	Queue int
+	// ranked, normal, tourney or tutorial
+	Type string
+	// Only matches that started in this window
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+func (c *RiotClient) RecentMatches(puuid string, query MatchQuery) ([]byte, error) {
+	params := url.Values{}
+	if query.Count > 0 {
+		params.Set("count", strconv.Itoa(query.Count))
+	}
+	if query.Start > 0 {
+		params.Set("start", strconv.Itoa(query.Start))
+	}
+	if query.Queue != 0 {
+		params.Set("queue", strconv.Itoa(query.Queue))
+	}
+	if query.Type != "" {
+		params.Set("type", query.Type)
+	}
+	if !query.StartTime.IsZero() {
+		params.Set("startTime", strconv.FormatInt(query.StartTime.Unix(), 10))
+	}
+	if !query.EndTime.IsZero() {
+		params.Set("endTime", strconv.FormatInt(query.EndTime.Unix(), 10))
+	}
+	requestURL := fmt.Sprintf("%s/lol/match/v5/matches/by-puuid/%s/ids?%s",
+		c.regionalURL(), puuid, params.Encode())
 
-	body, err := c.request(url)
+	body, err := c.request("match-v5/ids", requestURL)
 	if err != nil {
 		return nil, fmt.Errorf("error making request: %w", err)
 	}
@@ -108,12 +204,158 @@ func (c *RiotClient) RecentMatches(puuid string, count int) ([]byte, error) {
 
 func (c *RiotClient) MatchDetails(matchID string) ([]byte, error) {
 	url := fmt.Sprintf("%s/lol/match/v5/matches/%s",
-		fmt.Sprintf(baseURL, c.Region), matchID)
+		c.regionalURL(), matchID)
 
-	body, err := c.request(url)
+	body, err := c.request("match-v5/matches", url)
 	if err != nil {
 		return nil, fmt.Errorf("error making request: %w", err)
 	}
 
 	return body, nil
 }
+
+// MatchTimeline returns the minute by minute frames and events of a match
+func (c *RiotClient) MatchTimeline(matchID string) ([]byte, error) {
+	url := fmt.Sprintf("%s/lol/match/v5/matches/%s/timeline",
+		c.regionalURL(), matchID)
+
+	body, err := c.request("match-v5/timeline", url)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+
+	return body, nil
+}
+
+// NewBudget limits optional requests to a share of the client's request rate so they can't starve the rest.
+// Requests allowed by the budget still wait for the client's own limiter.
+func (c *RiotClient) NewBudget(share float64) *rate.Limiter {
+	return rate.NewLimiter(c.limiter.Limit()*rate.Limit(share), 1)
+}
+
+type Account struct {
+	PUUID    string `json:"puuid"`
+	GameName string `json:"gameName"`
+	TagLine  string `json:"tagLine"`
+}
+
+func (c *RiotClient) AccountByRiotID(gameName, tagLine string) (Account, error) {
+	requestURL := fmt.Sprintf("%s/riot/account/v1/accounts/by-riot-id/%s/%s",
+		c.regionalURL(), url.PathEscape(gameName), url.PathEscape(tagLine))
+
+	body, err := c.request("account-v1/by-riot-id", requestURL)
+	if err != nil {
+		return Account{}, fmt.Errorf("error making request: %w", err)
+	}
+
+	var account Account
+	if err := json.Unmarshal(body, &account); err != nil {
+		return Account{}, fmt.Errorf("error unmarshalling account: %w", err)
+	}
+
+	return account, nil
+}
+
+type ChampionMastery struct {
+	ChampionID     int32 `json:"championId"`
+	ChampionLevel  int   `json:"championLevel"`
+	ChampionPoints int   `json:"championPoints"`
+	LastPlayTime   int64 `json:"lastPlayTime"`
+}
+
+func (c *RiotClient) ChampionMasteries(server, puuid string) ([]ChampionMastery, error) {
+	url := fmt.Sprintf("%s/lol/champion-mastery/v4/champion-masteries/by-puuid/%s",
+		c.platformURL(server), puuid)
+
+	body, err := c.request("champion-mastery-v4", url)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+
+	var masteries []ChampionMastery
+	if err := json.Unmarshal(body, &masteries); err != nil {
+		return nil, fmt.Errorf("error unmarshalling champion masteries: %w", err)
+	}
+
+	return masteries, nil
+}
+
+type LeagueEntry struct {
+	PUUID        string `json:"puuid"`
+	QueueType    string `json:"queueType"`
+	Tier         string `json:"tier"`
+	Rank         string `json:"rank"`
+	LeaguePoints int    `json:"leaguePoints"`
+	Wins         int    `json:"wins"`
+	Losses       int    `json:"losses"`
+}
+
+// LeagueEntries returns the player's ranked entries, one per queue they are ranked in
+func (c *RiotClient) LeagueEntries(server, puuid string) ([]LeagueEntry, error) {
+	url := fmt.Sprintf("%s/lol/league/v4/entries/by-puuid/%s",
+		c.platformURL(server), puuid)
+
+	body, err := c.request("league-v4/entries/by-puuid", url)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+
+	var entries []LeagueEntry
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return nil, fmt.Errorf("error unmarshalling league entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// LeagueList is a whole apex tier, its entries don't repeat the tier
+type LeagueList struct {
+	Tier    string        `json:"tier"`
+	Entries []LeagueEntry `json:"entries"`
+}
+
+var apexLeaguePaths = map[string]string{
+	"CHALLENGER":  "challengerleagues",
+	"GRANDMASTER": "grandmasterleagues",
+	"MASTER":      "masterleagues",
+}
+
+// ApexLeague returns every solo queue player in challenger, grandmaster or master on a server
+func (c *RiotClient) ApexLeague(server, tier string) (LeagueList, error) {
+	path, ok := apexLeaguePaths[strings.ToUpper(tier)]
+	if !ok {
+		return LeagueList{}, fmt.Errorf("%s is not an apex tier", tier)
+	}
+	url := fmt.Sprintf("%s/lol/league/v4/%s/by-queue/RANKED_SOLO_5x5",
+		c.platformURL(server), path)
+
+	body, err := c.request("league-v4/apex", url)
+	if err != nil {
+		return LeagueList{}, fmt.Errorf("error making request: %w", err)
+	}
+
+	var list LeagueList
+	if err := json.Unmarshal(body, &list); err != nil {
+		return LeagueList{}, fmt.Errorf("error unmarshalling league list: %w", err)
+	}
+
+	return list, nil
+}
+
+// TierEntries returns one page of the solo queue players in a tier and division, pages start at 1 and an empty page is the end
+func (c *RiotClient) TierEntries(server, tier, division string, page int) ([]LeagueEntry, error) {
+	url := fmt.Sprintf("%s/lol/league/v4/entries/RANKED_SOLO_5x5/%s/%s?page=%d",
+		c.platformURL(server), tier, division, page)
+
+	body, err := c.request("league-v4/entries", url)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+
+	var entries []LeagueEntry
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return nil, fmt.Errorf("error unmarshalling league entries: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,195 @@
+// Package riottest serves the account-v1, champion-mastery-v4 and match-v5 endpoints the recommender uses
+// from memory, so code that calls the Riot API can be tested without a key or the network.
+package riottest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	"lol-champ-recommender/internal/api"
+
+	"golang.org/x/time/rate"
+)
+
+// Key is the API key clients made by Client send, requests without it get a 401 like from Riot
+const Key = "RGAPI-test"
+
+// Participant is the part of a match-v5 participant the fake server fills in
+type Participant struct {
+	PUUID      string
+	ChampionID int32
+	TeamID     int32
+	Win        bool
+}
+
+// Server is a fake Riot API. Fill it in with AddAccount, AddMastery and AddMatch before making requests.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	accounts  map[string]api.Account
+	masteries map[string][]api.ChampionMastery
+	// Newest first, like match-v5 lists them
+	matchIDs map[string][]string
+	matches  map[string][]byte
+	requests []Request
+}
+
+// Request is a request the server received. Routing is the host's routing value, e.g. americas or na1.
+type Request struct {
+	Routing string
+	Path    string
+	Query   string
+}
+
+func NewServer() *Server {
+	s := &Server{
+		accounts:  make(map[string]api.Account),
+		masteries: make(map[string][]api.ChampionMastery),
+		matchIDs:  make(map[string][]string),
+		matches:   make(map[string][]byte),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Client returns a client for region that sends its requests to the server, without a rate limit
+func (s *Server) Client(ctx context.Context, region string) *api.RiotClient {
+	client, err := api.NewRiotClient(Key, region, ctx)
+	if err != nil {
+		panic(err)
+	}
+	// The routing value becomes the first path segment instead of the subdomain
+	client.BaseURL = s.URL + "/%s"
+	client.SetRateLimit(rate.Inf, 1)
+	return client
+}
+
+func (s *Server) AddAccount(account api.Account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accounts[account.GameName+"#"+account.TagLine] = account
+}
+
+func (s *Server) AddMastery(puuid string, mastery api.ChampionMastery) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.masteries[puuid] = append(s.masteries[puuid], mastery)
+}
+
+// AddMatch stores a ranked solo/duo match and lists it as the newest match of each participant
+func (s *Server) AddMatch(matchID string, participants ...Participant) {
+	type participant struct {
+		PUUID      string `json:"puuid"`
+		ChampionID int32  `json:"championId"`
+		TeamID     int32  `json:"teamId"`
+		Win        bool   `json:"win"`
+	}
+	var match struct {
+		Metadata struct {
+			MatchID      string   `json:"matchId"`
+			Participants []string `json:"participants"`
+		} `json:"metadata"`
+		Info struct {
+			QueueID      int           `json:"queueId"`
+			Participants []participant `json:"participants"`
+		} `json:"info"`
+	}
+	match.Metadata.MatchID = matchID
+	match.Info.QueueID = 420
+	for _, p := range participants {
+		match.Metadata.Participants = append(match.Metadata.Participants, p.PUUID)
+		match.Info.Participants = append(match.Info.Participants, participant(p))
+	}
+	body, err := json.Marshal(match)
+	if err != nil {
+		panic(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.matches[matchID] = body
+	for _, p := range participants {
+		s.matchIDs[p.PUUID] = append([]string{matchID}, s.matchIDs[p.PUUID]...)
+	}
+}
+
+// Requests returns the requests received so far, oldest first
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request{}, s.requests...)
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	routing, path, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	path = "/" + path
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, Request{Routing: routing, Path: path, Query: r.URL.RawQuery})
+
+	if r.Header.Get("X-Riot-Token") != Key {
+		http.Error(w, `{"status":{"message":"Unauthorized","status_code":401}}`, http.StatusUnauthorized)
+		return
+	}
+
+	if riotID, ok := strings.CutPrefix(path, "/riot/account/v1/accounts/by-riot-id/"); ok {
+		gameName, tagLine, _ := strings.Cut(riotID, "/")
+		account, ok := s.accounts[gameName+"#"+tagLine]
+		if !ok {
+			notFound(w)
+			return
+		}
+		writeJSON(w, account)
+		return
+	}
+
+	if puuid, ok := strings.CutPrefix(path, "/lol/champion-mastery/v4/champion-masteries/by-puuid/"); ok {
+		// Riot answers with an empty list for players without mastery
+		masteries := append([]api.ChampionMastery{}, s.masteries[puuid]...)
+		writeJSON(w, masteries)
+		return
+	}
+
+	if rest, ok := strings.CutPrefix(path, "/lol/match/v5/matches/by-puuid/"); ok {
+		puuid, _ := strings.CutSuffix(rest, "/ids")
+		ids := s.matchIDs[puuid]
+		start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+		count := 20
+		if value := r.URL.Query().Get("count"); value != "" {
+			count, _ = strconv.Atoi(value)
+		}
+		ids = ids[min(start, len(ids)):]
+		ids = ids[:min(count, len(ids))]
+		writeJSON(w, append([]string{}, ids...))
+		return
+	}
+
+	if matchID, ok := strings.CutPrefix(path, "/lol/match/v5/matches/"); ok {
+		body, ok := s.matches[matchID]
+		if !ok {
+			notFound(w)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+		return
+	}
+
+	notFound(w)
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(value)
+}
+
+func notFound(w http.ResponseWriter) {
+	http.Error(w, `{"status":{"message":"Data not found","status_code":404}}`, http.StatusNotFound)
+}
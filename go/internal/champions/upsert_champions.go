@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"lol-champ-recommender/db"
 	"net/http"
 	"strconv"
@@ -84,7 +85,7 @@ func UpsertChampions(ctx context.Context, queries *db.Queries) error {
 	}
 
 	shortenedVersion := toMajorMinorOne(version)
-	fmt.Println("Upserting champions from version", shortenedVersion)
+	slog.Info("Upserting champions", "version", shortenedVersion)
 
 	err = upsertChampionsFromVersion(ctx, queries, shortenedVersion)
 	return err
@@ -0,0 +1,326 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"lol-champ-recommender/internal/api"
+	"lol-champ-recommender/internal/database"
+	"lol-champ-recommender/internal/logging"
+	"lol-champ-recommender/internal/rank"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Used when neither -config nor LOLREC_CONFIG is set. Relative to the go directory, like the other paths.
+const DefaultPath = "config/lolrec.yaml"
+
+type Config struct {
+	Database    DatabaseConfig    `yaml:"database"`
+	Riot        RiotConfig        `yaml:"riot"`
+	Crawler     CrawlerConfig     `yaml:"crawler"`
+	Recommender RecommenderConfig `yaml:"recommender"`
+	Export      ExportConfig      `yaml:"export"`
+	Log         LogConfig         `yaml:"log"`
+}
+
+type DatabaseConfig struct {
+	// Usually left empty in the file and set with DATABASE_URL
+	URL              string        `yaml:"url"`
+	MaxConns         int           `yaml:"max_conns"`
+	StatementTimeout time.Duration `yaml:"statement_timeout"`
+}
+
+type RiotConfig struct {
+	// Usually left empty in the file and set with RIOT_API_KEY
+	APIKey string `yaml:"api_key"`
+}
+
+type CrawlerConfig struct {
+	// Regional routes to crawl, one crawler runs per region
+	Regions []string `yaml:"regions"`
+	// How long to wait before crawling a player again the first time. After that the wait adapts to how many
+	// new matches the player had, staying between recrawl_min and recrawl_max.
+	RecrawlAfter time.Duration `yaml:"recrawl_after"`
+	RecrawlMin   time.Duration `yaml:"recrawl_min"`
+	RecrawlMax   time.Duration `yaml:"recrawl_max"`
+	// How many of a player's recent matches to fetch, at most 100
+	MatchCount int `yaml:"match_count"`
+	// Match-v5 queue ids to crawl, e.g. 420 for ranked solo/duo. Matches from other queues are never fetched.
+	Queues []int `yaml:"queues"`
+	// Where to start crawling a region that has no matches yet, keyed by region
+	SeedAccounts map[string]SeedAccount `yaml:"seed_accounts"`
+	// Where players come from: accounts walks out from the seed accounts through crawled matches,
+	// ladder takes them from the league-v4 ladder of the seed account's server first
+	SeedMode string `yaml:"seed_mode"`
+	// Players in this tier range are crawled before anyone else, and the ladder is only read inside it.
+	// Either end can be left empty.
+	MinTier string `yaml:"min_tier"`
+	MaxTier string `yaml:"max_tier"`
+	// Matches are buffered and written together once this many are waiting
+	BatchSize int `yaml:"batch_size"`
+	// Buffered matches are written at least this often
+	FlushInterval time.Duration `yaml:"flush_interval"`
+	// Also fetch match timelines for gold and xp differentials and first objectives
+	Timelines bool `yaml:"timelines"`
+	// The most of the request rate timelines may use, matches whose timeline doesn't fit are saved without one
+	TimelineShare float64 `yaml:"timeline_share"`
+	// Also page back through every crawled player's history to this date (2006-01-02), empty to only fetch recent matches
+	BackfillSince string `yaml:"backfill_since"`
+	// The most of the request rate backfilling may use, the rest goes to finding new players
+	BackfillShare float64 `yaml:"backfill_share"`
+	// Fetch the solo queue rank of every participant to tag matches with their average rank
+	Ranks bool `yaml:"ranks"`
+	// How long a fetched rank is reused before it is fetched again
+	RankTTL time.Duration `yaml:"rank_ttl"`
+	// The most of the request rate rank lookups may use, matches whose ranks don't fit are saved without one
+	RankShare float64 `yaml:"rank_share"`
+	// Address to serve Prometheus metrics on at /metrics, e.g. :9090, empty to not serve them
+	MetricsAddr string `yaml:"metrics_addr"`
+	// How often a one line summary of the crawl is printed
+	SummaryInterval time.Duration `yaml:"summary_interval"`
+	// How long crawlers get to save their progress on shutdown before the crawl exits without them
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+type SeedAccount struct {
+	PUUID    string `yaml:"puuid"`
+	Server   string `yaml:"server"`
+	Username string `yaml:"username"`
+}
+
+type RecommenderConfig struct {
+	ChampionPoolsFile string `yaml:"champion_pools_file"`
+	// Where recommend -riot-id looks players up when -region and -server aren't passed
+	Region string `yaml:"region"`
+	Server string `yaml:"server"`
+}
+
+type ExportConfig struct {
+	// Where write_json_to_next writes the website's data
+	OutputDir string `yaml:"output_dir"`
+}
+
+type LogConfig struct {
+	// debug, info, warn or error
+	Level string `yaml:"level"`
+	// text or json
+	Format string `yaml:"format"`
+}
+
+var knownRegions = []string{"americas", "asia", "europe", "sea"}
+var knownSeedModes = []string{"accounts", "ladder"}
+
+func Default() *Config {
+	return &Config{
+		Crawler: CrawlerConfig{
+			Regions:         append([]string{}, knownRegions...),
+			RecrawlAfter:    48 * time.Hour,
+			RecrawlMin:      6 * time.Hour,
+			RecrawlMax:      30 * 24 * time.Hour,
+			MatchCount:      20,
+			Queues:          []int{420, 440},
+			SeedMode:        "accounts",
+			BatchSize:       100,
+			FlushInterval:   10 * time.Second,
+			TimelineShare:   0.25,
+			BackfillShare:   0.5,
+			Ranks:           true,
+			RankTTL:         7 * 24 * time.Hour,
+			RankShare:       0.5,
+			SummaryInterval: time.Minute,
+			ShutdownTimeout: 10 * time.Second,
+		},
+		Recommender: RecommenderConfig{
+			ChampionPoolsFile: "config/champion_pools.json",
+			Region:            "americas",
+			Server:            "NA1",
+		},
+		Export: ExportConfig{
+			OutputDir: "../next/src/data/",
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "text",
+		},
+	}
+}
+
+// Load builds the config from the defaults, then the file at path, then the environment.
+// Pass an empty path to use LOLREC_CONFIG or DefaultPath. Only DefaultPath may be missing.
+func Load(path string) (*Config, error) {
+	if err := database.LoadEnv(); err != nil {
+		return nil, err
+	}
+
+	config := Default()
+
+	if path == "" {
+		path = os.Getenv("LOLREC_CONFIG")
+	}
+	optional := path == ""
+	if optional {
+		path = DefaultPath
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !(optional && errors.Is(err, fs.ErrNotExist)) {
+		return nil, fmt.Errorf("error reading config: %w", err)
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("error parsing config %s: %w", path, err)
+		}
+	}
+
+	if err := config.applyEnv(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Database.MaxConns < 0 {
+		errs = append(errs, fmt.Errorf("database.max_conns must not be negative"))
+	}
+	if c.Database.StatementTimeout < 0 {
+		errs = append(errs, fmt.Errorf("database.statement_timeout must not be negative"))
+	}
+
+	if len(c.Crawler.Regions) == 0 {
+		errs = append(errs, fmt.Errorf("crawler.regions must not be empty"))
+	}
+	for _, region := range c.Crawler.Regions {
+		if !contains(knownRegions, region) {
+			errs = append(errs, fmt.Errorf("crawler.regions: unknown region %q, expected one of %v", region, knownRegions))
+		}
+	}
+	for region, account := range c.Crawler.SeedAccounts {
+		if account.PUUID == "" || account.Server == "" {
+			errs = append(errs, fmt.Errorf("crawler.seed_accounts.%s: puuid and server are required", region))
+		}
+	}
+	if c.Crawler.RecrawlAfter <= 0 {
+		errs = append(errs, fmt.Errorf("crawler.recrawl_after must be positive"))
+	}
+	if c.Crawler.RecrawlMin <= 0 || c.Crawler.RecrawlMin > c.Crawler.RecrawlAfter || c.Crawler.RecrawlAfter > c.Crawler.RecrawlMax {
+		errs = append(errs, fmt.Errorf("crawler.recrawl_min, recrawl_after and recrawl_max must be positive and in that order"))
+	}
+	if c.Crawler.MatchCount < 1 || c.Crawler.MatchCount > 100 {
+		errs = append(errs, fmt.Errorf("crawler.match_count must be between 1 and 100"))
+	}
+	if len(c.Crawler.Queues) == 0 {
+		errs = append(errs, fmt.Errorf("crawler.queues must not be empty"))
+	}
+	for _, queue := range c.Crawler.Queues {
+		if _, ok := api.Queues[queue]; !ok {
+			errs = append(errs, fmt.Errorf("crawler.queues: unknown queue %d, expected one of %v", queue, api.QueueIDs()))
+		}
+	}
+
+	if !contains(knownSeedModes, c.Crawler.SeedMode) {
+		errs = append(errs, fmt.Errorf("crawler.seed_mode: unknown mode %q, expected one of %v", c.Crawler.SeedMode, knownSeedModes))
+	}
+	if _, err := c.Crawler.Bracket(); err != nil {
+		errs = append(errs, fmt.Errorf("crawler.min_tier, crawler.max_tier: %w", err))
+	}
+	if c.Crawler.BatchSize < 1 {
+		errs = append(errs, fmt.Errorf("crawler.batch_size must be at least 1"))
+	}
+	if c.Crawler.FlushInterval <= 0 {
+		errs = append(errs, fmt.Errorf("crawler.flush_interval must be positive"))
+	}
+	if c.Crawler.RankTTL <= 0 {
+		errs = append(errs, fmt.Errorf("crawler.rank_ttl must be positive"))
+	}
+	if c.Crawler.RankShare <= 0 || c.Crawler.RankShare > 1 {
+		errs = append(errs, fmt.Errorf("crawler.rank_share must be above 0 and at most 1"))
+	}
+	if _, _, err := c.Crawler.Backfill(); err != nil {
+		errs = append(errs, fmt.Errorf("crawler.backfill_since: %w", err))
+	}
+	if c.Crawler.BackfillShare <= 0 || c.Crawler.BackfillShare > 1 {
+		errs = append(errs, fmt.Errorf("crawler.backfill_share must be above 0 and at most 1"))
+	}
+	if c.Crawler.TimelineShare <= 0 || c.Crawler.TimelineShare > 1 {
+		errs = append(errs, fmt.Errorf("crawler.timeline_share must be above 0 and at most 1"))
+	}
+	if c.Crawler.SummaryInterval <= 0 {
+		errs = append(errs, fmt.Errorf("crawler.summary_interval must be positive"))
+	}
+	if c.Crawler.ShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("crawler.shutdown_timeout must be positive"))
+	}
+
+	if !contains(knownRegions, c.Recommender.Region) {
+		errs = append(errs, fmt.Errorf("recommender.region: unknown region %q, expected one of %v", c.Recommender.Region, knownRegions))
+	}
+	if c.Recommender.Server == "" {
+		errs = append(errs, fmt.Errorf("recommender.server must not be empty"))
+	}
+
+	if c.Export.OutputDir == "" {
+		errs = append(errs, fmt.Errorf("export.output_dir must not be empty"))
+	}
+
+	if !contains(logging.Levels, c.Log.Level) {
+		errs = append(errs, fmt.Errorf("log.level: unknown level %q, expected one of %v", c.Log.Level, logging.Levels))
+	}
+	if !contains(logging.Formats, c.Log.Format) {
+		errs = append(errs, fmt.Errorf("log.format: unknown format %q, expected one of %v", c.Log.Format, logging.Formats))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (d DatabaseConfig) Options() database.Options {
+	return database.Options{
+		DSN:              d.URL,
+		MaxConns:         d.MaxConns,
+		StatementTimeout: d.StatementTimeout,
+	}
+}
+
+// ValidateSeeds checks every crawled region has a seed account. Only crawling needs them, so Validate leaves it out.
+func (c CrawlerConfig) ValidateSeeds() error {
+	var errs []error
+	for _, region := range c.Regions {
+		// Crawls start from the seed account and the ladder is read from its server
+		if _, ok := c.SeedAccounts[region]; !ok {
+			errs = append(errs, fmt.Errorf("crawler.seed_accounts: no seed account for region %q in crawler.regions", region))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Backfill is the date to backfill to, false when backfilling is off
+func (c CrawlerConfig) Backfill() (time.Time, bool, error) {
+	if c.BackfillSince == "" {
+		return time.Time{}, false, nil
+	}
+	since, err := time.Parse(time.DateOnly, c.BackfillSince)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("expected a date like 2006-01-02: %w", err)
+	}
+	return since, true, nil
+}
+
+// Bracket is the tier range players are prioritized in
+func (c CrawlerConfig) Bracket() (rank.Bracket, error) {
+	return rank.ParseBracket(c.MinTier, c.MaxTier)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
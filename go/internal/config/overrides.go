@@ -0,0 +1,186 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables that predate the config file and keep working
+var legacyEnv = map[string]string{
+	"DATABASE_URL":               "database.url",
+	"DATABASE_MAX_CONNS":         "database.max_conns",
+	"DATABASE_STATEMENT_TIMEOUT": "database.statement_timeout",
+	"RIOT_API_KEY":               "riot.api_key",
+}
+
+// Flags are the config flags shared by every command
+type Flags struct {
+	path      string
+	overrides []string
+}
+
+// RegisterFlags adds -config and -set. Call Load on the result once the flags have been parsed.
+func RegisterFlags(flags *flag.FlagSet) *Flags {
+	f := &Flags{}
+	flags.StringVar(&f.path, "config", "", "path to the config file (default $LOLREC_CONFIG or "+DefaultPath+")")
+	flags.Func("set", "override a config value, e.g. -set crawler.match_count=50 (repeatable)", func(value string) error {
+		f.overrides = append(f.overrides, value)
+		return nil
+	})
+	return f
+}
+
+// Load loads the config file and environment, applies -set overrides and validates the result
+func (f *Flags) Load() (*Config, error) {
+	config, err := Load(f.path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, override := range f.overrides {
+		key, value, ok := strings.Cut(override, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid -set %q, expected key=value", override)
+		}
+		if err := config.Set(key, value); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return config, nil
+}
+
+// applyEnv overrides values from the environment. Every value can be set with LOLREC_ followed by
+// its key in upper case with dots replaced by underscores, e.g. LOLREC_CRAWLER_MATCH_COUNT.
+func (c *Config) applyEnv() error {
+	for env, key := range legacyEnv {
+		if value, ok := os.LookupEnv(env); ok {
+			if err := c.Set(key, value); err != nil {
+				return fmt.Errorf("%s: %w", env, err)
+			}
+		}
+	}
+
+	for _, key := range keys(reflect.TypeOf(*c), "") {
+		env := "LOLREC_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+		if value, ok := os.LookupEnv(env); ok {
+			if err := c.Set(key, value); err != nil {
+				return fmt.Errorf("%s: %w", env, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Set overrides the value at a dotted key like crawler.regions. Lists are comma separated.
+func (c *Config) Set(key string, value string) error {
+	field := reflect.ValueOf(c).Elem()
+	for _, name := range strings.Split(key, ".") {
+		if field.Kind() != reflect.Struct {
+			return fmt.Errorf("unknown config key %q", key)
+		}
+		next, ok := fieldByTag(field, name)
+		if !ok {
+			return fmt.Errorf("unknown config key %q", key)
+		}
+		field = next
+	}
+
+	if err := setValue(field, value); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	return nil
+}
+
+func fieldByTag(structValue reflect.Value, name string) (reflect.Value, bool) {
+	for i := 0; i < structValue.NumField(); i++ {
+		if yamlName(structValue.Type().Field(i)) == name {
+			return structValue.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func setValue(field reflect.Value, value string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(number))
+	case reflect.Float64:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(number)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Slice:
+		values := reflect.MakeSlice(field.Type(), 0, 0)
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v == "" {
+				continue
+			}
+			element := reflect.New(field.Type().Elem()).Elem()
+			if err := setValue(element, v); err != nil {
+				return err
+			}
+			values = reflect.Append(values, element)
+		}
+		field.Set(values)
+	default:
+		return fmt.Errorf("cannot be set from a string")
+	}
+
+	return nil
+}
+
+// keys lists the dotted keys of every value that can be set from a string
+func keys(t reflect.Type, prefix string) []string {
+	var result []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := prefix + yamlName(field)
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			result = append(result, keys(field.Type, key+".")...)
+		case reflect.Map:
+			continue
+		default:
+			result = append(result, key)
+		}
+	}
+	return result
+}
+
+func yamlName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	return name
+}
@@ -0,0 +1,127 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"lol-champ-recommender/db"
+	"lol-champ-recommender/internal/api"
+	"lol-champ-recommender/internal/metrics"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Match ids asked for per backfill page, the most match-v5 returns
+const backfillPageSize = 100
+
+// backfillPlayer pages back through the player's history in every configured queue until the backfill date,
+// continuing from where the last backfill of the player stopped. It stops early once BackfillBudget runs out.
+func (c *Crawler) backfillPlayer(ctx context.Context, puuid string) error {
+	since, _, err := c.Config.Backfill()
+	if err != nil {
+		return err
+	}
+
+	for _, queue := range c.Config.Queues {
+		more, err := c.backfillQueue(ctx, puuid, queue, since)
+		if err != nil {
+			return fmt.Errorf("error backfilling queue %d: %w", queue, err)
+		}
+		if more {
+			c.logger().Debug("Backfill budget used up, continuing next time", "puuid", puuid)
+			return nil
+		}
+	}
+	return nil
+}
+
+// backfillQueue returns true when the budget ran out before the player's history in the queue was done
+func (c *Crawler) backfillQueue(ctx context.Context, puuid string, queue int, since time.Time) (bool, error) {
+	cursor, err := c.Queries.BackfillCursor(c.Ctx, db.BackfillCursorParams{Puuid: puuid, QueueID: int32(queue)})
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return false, fmt.Errorf("error getting backfill cursor: %w", err)
+	}
+	if err != nil || !cursor.StartTime.Time.Equal(since) {
+		cursor = db.BackfillCursor{
+			Puuid:     puuid,
+			QueueID:   int32(queue),
+			StartTime: pgtype.Timestamp{Time: since, Valid: true},
+			EndTime:   pgtype.Timestamp{Time: time.Now().UTC().Truncate(time.Second), Valid: true},
+		}
+	}
+
+	for !cursor.Done {
+		if !c.BackfillBudget.Allow() {
+			return true, c.saveBackfillCursor(cursor)
+		}
+		body, err := c.Client.RecentMatches(puuid, api.MatchQuery{
+			Count:     backfillPageSize,
+			Start:     int(cursor.NextStart),
+			Queue:     queue,
+			StartTime: since,
+			EndTime:   cursor.EndTime.Time,
+		})
+		if err != nil {
+			return false, err
+		}
+		var matchIDs []string
+		if err := json.Unmarshal(body, &matchIDs); err != nil {
+			return false, fmt.Errorf("error unmarshalling match IDs: %w", err)
+		}
+
+		existing, err := c.Queries.ExistingMatchIDs(c.Ctx, matchIDs)
+		if err != nil {
+			return false, fmt.Errorf("error checking which matches exist: %w", err)
+		}
+		matchExists := make(map[string]bool, len(existing))
+		for _, matchID := range existing {
+			matchExists[matchID] = true
+		}
+
+		for _, matchID := range matchIDs {
+			if matchExists[matchID] || c.Writer.Pending(matchID) {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				return false, err
+			}
+			// The cursor stays at the start of the page, the matches already fetched are skipped next time
+			if !c.BackfillBudget.Allow() {
+				return true, c.saveBackfillCursor(cursor)
+			}
+			if err := c.createMatch(matchID); err != nil {
+				c.logger().Error("Error creating backfilled match", "puuid", puuid, "match_id", matchID, "err", err)
+				metrics.Matches.WithLabelValues(c.Client.Region, "failed").Inc()
+			} else {
+				metrics.Matches.WithLabelValues(c.Client.Region, "queued").Inc()
+			}
+		}
+
+		cursor.NextStart += int32(len(matchIDs))
+		cursor.Done = len(matchIDs) < backfillPageSize
+		if err := c.saveBackfillCursor(cursor); err != nil {
+			return false, err
+		}
+		c.logger().Info("Backfilled matches", "puuid", puuid, "queue", queue, "matches", cursor.NextStart)
+	}
+
+	return false, nil
+}
+
+func (c *Crawler) saveBackfillCursor(cursor db.BackfillCursor) error {
+	err := c.Queries.UpsertBackfillCursor(c.Ctx, db.UpsertBackfillCursorParams{
+		Puuid:     cursor.Puuid,
+		QueueID:   cursor.QueueID,
+		StartTime: cursor.StartTime,
+		EndTime:   cursor.EndTime,
+		NextStart: cursor.NextStart,
+		Done:      cursor.Done,
+	})
+	if err != nil {
+		return fmt.Errorf("error saving backfill cursor: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"lol-champ-recommender/db"
+)
+
+// resumeCheckpoints finishes the region's crawls that were cut short the last time it ran. Every match listed
+// for the player that still isn't saved is fetched, which also covers matches that were queued but never written.
+func (c *Crawler) resumeCheckpoints(ctx context.Context) error {
+	checkpoints, err := c.Queries.CrawlCheckpointsForRegion(c.Ctx, c.Client.Region)
+	if err != nil {
+		return fmt.Errorf("error getting crawl checkpoints: %w", err)
+	}
+
+	for _, checkpoint := range checkpoints {
+		checkpoint.Remaining, err = c.unsavedMatches(checkpoint.MatchIds)
+		if err != nil {
+			return err
+		}
+		c.logger().Info("Resuming crawl", "puuid", checkpoint.Puuid, "remaining", len(checkpoint.Remaining))
+
+		err = c.crawlCheckpoint(ctx, checkpoint)
+		if err != nil {
+			if err == ctx.Err() {
+				return err
+			}
+			c.logger().Error("Error resuming crawl", "puuid", checkpoint.Puuid, "err", err)
+		}
+	}
+	return nil
+}
+
+// unsavedMatches leaves out the matches that are already saved or waiting to be written
+func (c *Crawler) unsavedMatches(matchIDs []string) ([]string, error) {
+	// One query for the whole page instead of one per match
+	existing, err := c.Queries.ExistingMatchIDs(c.Ctx, matchIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error checking which matches exist: %w", err)
+	}
+	matchExists := make(map[string]bool, len(existing))
+	for _, matchID := range existing {
+		matchExists[matchID] = true
+	}
+
+	remaining := []string{}
+	for _, matchID := range matchIDs {
+		if !matchExists[matchID] && !c.Writer.Pending(matchID) {
+			remaining = append(remaining, matchID)
+		}
+	}
+	return remaining, nil
+}
+
+// drain saves the checkpoint with the matches from next on still to fetch, then returns why the crawl stopped
+func (c *Crawler) drain(ctx context.Context, checkpoint db.CrawlCheckpoint, next int) error {
+	checkpoint.Remaining = checkpoint.Remaining[next:]
+	if err := c.saveCheckpoint(checkpoint); err != nil {
+		c.logger().Error("Error saving crawl checkpoint", "puuid", checkpoint.Puuid, "err", err)
+	} else {
+		c.logger().Info("Saved crawl checkpoint", "puuid", checkpoint.Puuid, "remaining", len(checkpoint.Remaining))
+	}
+	return ctx.Err()
+}
+
+func (c *Crawler) saveCheckpoint(checkpoint db.CrawlCheckpoint) error {
+	// Also saved while shutting down, after c.Ctx is cancelled. Nil slices would be sent as NULL.
+	err := c.Queries.UpsertCrawlCheckpoint(context.WithoutCancel(c.Ctx), db.UpsertCrawlCheckpointParams{
+		Puuid:      checkpoint.Puuid,
+		Region:     checkpoint.Region,
+		MatchIds:   append([]string{}, checkpoint.MatchIds...),
+		Remaining:  append([]string{}, checkpoint.Remaining...),
+		NewMatches: checkpoint.NewMatches,
+	})
+	if err != nil {
+		return fmt.Errorf("error saving crawl checkpoint: %w", err)
+	}
+	return nil
+}
@@ -3,19 +3,44 @@ package crawler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"lol-champ-recommender/db"
 	"lol-champ-recommender/internal/api"
-	"os"
+	"lol-champ-recommender/internal/config"
+	"lol-champ-recommender/internal/ingest"
+	"lol-champ-recommender/internal/metrics"
+	"lol-champ-recommender/internal/rank"
+	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/time/rate"
 )
 
 type Crawler struct {
 	Queries *db.Queries
 	Client  *api.RiotClient
 	Ctx     context.Context
+	Config  config.CrawlerConfig
+	// Shared by every region's crawler
+	Writer *ingest.BatchWriter
+	// Timelines are only fetched when set, and only while the budget allows
+	TimelineBudget *rate.Limiter
+	// Tags matches with their average rank when set
+	Ranks *rank.Cache
+	// Players' histories are backfilled when set, only while the budget allows
+	BackfillBudget *rate.Limiter
+
+	// Read from when Config.SeedMode is ladder, created on first use
+	ladder *Ladder
+}
+
+// logger tags everything the crawler logs with its region
+func (c *Crawler) logger() *slog.Logger {
+	return slog.With("region", c.Client.Region)
 }
 
 type Match struct {
@@ -24,14 +49,24 @@ type Match struct {
 	} `json:"metadata"`
 	Info struct {
 		EndOfGameResult    string `json:"endOfGameResult"`
+		GameDuration       int64  `json:"gameDuration"`
+		GameEndTimestamp   int64  `json:"gameEndTimestamp"`
 		GameStartTimestamp int64  `json:"gameStartTimestamp"`
 		GameVersion        string `json:"gameVersion"`
 		QueueID            int    `json:"queueId"`
 		PlatformID         string `json:"platformId"`
 		Participants       []struct {
+			Puuid        string `json:"puuid"`
 			ChampionName string `json:"championName"`
 			ChampionID   int    `json:"championId"`
 			TeamID       int    `json:"teamId"`
+			TeamPosition string `json:"teamPosition"`
+			Win          bool   `json:"win"`
+			Kills        int    `json:"kills"`
+			Deaths       int    `json:"deaths"`
+			Assists      int    `json:"assists"`
+			// Set when the game was ended by a remake vote
+			GameEndedInEarlySurrender bool `json:"gameEndedInEarlySurrender"`
 		} `json:"participants"`
 		Teams []struct {
 			TeamID int  `json:"teamId"`
@@ -48,12 +83,14 @@ type MatchPuuids struct {
 	}
 }
 
-type SeedAccount struct {
-	PUUID  string `json:"puuid"`
-	Server string `json:"server"`
-}
-
 func (c *Crawler) RunCrawler(runCtx context.Context) error {
+	if err := c.resumeCheckpoints(runCtx); err != nil {
+		if err == runCtx.Err() {
+			return err
+		}
+		c.logger().Error("Error resuming crawls", "err", err)
+	}
+
 	for {
 		select {
 		case <-runCtx.Done():
@@ -61,16 +98,16 @@ func (c *Crawler) RunCrawler(runCtx context.Context) error {
 		default:
 			puuid, err := c.findNextPlayer()
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error finding next player: %v\n", err)
+				c.logger().Error("Error finding next player", "err", err)
 			}
-			fmt.Printf("Crawling player: %s\n", puuid)
+			c.logger().Info("Crawling player", "puuid", puuid)
 
 			err = c.crawlPlayer(runCtx, puuid)
 			if err != nil {
 				if err == runCtx.Err() {
 					return err
 				}
-				fmt.Fprintf(os.Stderr, "Error during crawl: %v\n", err)
+				c.logger().Error("Error during crawl", "puuid", puuid, "err", err)
 			}
 		}
 	}
@@ -82,38 +119,131 @@ func (c *Crawler) crawlPlayer(ctx context.Context, puuid string) error {
 		return err
 	}
 
-	for _, matchID := range matchIDs {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-			err = c.createMatch(matchID)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error creating match: %v\n", err)
+	remaining, err := c.unsavedMatches(matchIDs)
+	if err != nil {
+		return err
+	}
+	if duplicates := len(matchIDs) - len(remaining); duplicates > 0 {
+		c.logger().Debug("Matches already exist", "puuid", puuid, "matches", duplicates)
+		metrics.Matches.WithLabelValues(c.Client.Region, "duplicate").Add(float64(duplicates))
+	}
+
+	return c.crawlCheckpoint(ctx, db.CrawlCheckpoint{
+		Puuid:     puuid,
+		Region:    c.Client.Region,
+		MatchIds:  matchIDs,
+		Remaining: remaining,
+	})
+}
+
+// crawlCheckpoint fetches the checkpoint's remaining matches, backfills the player and schedules them again.
+// The checkpoint is saved before anything is fetched and deleted once the player is scheduled and the writer
+// has written their matches. When ctx is cancelled part way the matches still to fetch are saved to it instead,
+// and the crawl resumes after a restart.
+func (c *Crawler) crawlCheckpoint(ctx context.Context, checkpoint db.CrawlCheckpoint) error {
+	puuid := checkpoint.Puuid
+	if err := c.saveCheckpoint(checkpoint); err != nil {
+		return err
+	}
+
+	for i, matchID := range checkpoint.Remaining {
+		if ctx.Err() != nil {
+			return c.drain(ctx, checkpoint, i)
+		}
+		err := c.createMatch(matchID)
+		if err != nil && ctx.Err() != nil {
+			// Cut off by the shutdown rather than failed, it is fetched again after the restart
+			return c.drain(ctx, checkpoint, i)
+		}
+		if err != nil {
+			c.logger().Error("Error creating match", "puuid", puuid, "match_id", matchID, "err", err)
+			metrics.Matches.WithLabelValues(c.Client.Region, "failed").Inc()
+			continue
+		}
+		metrics.Matches.WithLabelValues(c.Client.Region, "queued").Inc()
+		checkpoint.NewMatches++
+	}
+
+	if c.BackfillBudget != nil {
+		if err := c.backfillPlayer(ctx, puuid); err != nil {
+			if ctx.Err() != nil {
+				// The backfill cursor has its own progress, the player still has to be scheduled
+				return c.drain(ctx, checkpoint, len(checkpoint.Remaining))
 			}
+			c.logger().Error("Error backfilling", "puuid", puuid, "err", err)
 		}
 	}
 
+	metrics.PlayersCrawled.WithLabelValues(c.Client.Region).Inc()
+
 	// Log the search
-	err = c.Queries.LogPlayerSearch(c.Ctx, puuid)
+	err := c.Queries.LogPlayerSearch(c.Ctx, puuid)
+	if err != nil {
+		c.logger().Error("Error logging player search", "puuid", puuid, "err", err)
+	}
+	// Backfilled matches are old, only recent ones say how active the player is
+	err = c.scheduleNext(puuid, int(checkpoint.NewMatches))
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error logging player search: %v\n", err)
+		c.logger().Error("Error scheduling player", "puuid", puuid, "err", err)
 	}
 
+	// The player's matches can still be buffered, the checkpoint is what refetches them if they are never written
+	c.Writer.AfterWrite(checkpoint.Remaining, func() {
+		err := c.Queries.DeleteCrawlCheckpoint(context.WithoutCancel(c.Ctx), puuid)
+		if err != nil {
+			c.logger().Error("Error deleting crawl checkpoint", "puuid", puuid, "err", err)
+		}
+	})
+
 	return nil
 }
 
-func saveMatch(queries *db.Queries, match *Match) error {
+// BuildMatch decodes a match-v5 response into the rows saved for it. The raw response is not archived,
+// set Raw on the result for that.
+func BuildMatch(matchData []byte) (ingest.Match, error) {
+	var match Match
+	if err := json.Unmarshal(matchData, &match); err != nil {
+		return ingest.Match{}, fmt.Errorf("error unmarshalling match data: %w", err)
+	}
+
+	params, err := matchParams(&match)
+	if err != nil {
+		return ingest.Match{}, err
+	}
+
+	participants := make([]ingest.Participant, len(match.Info.Participants))
+	for i, participant := range match.Info.Participants {
+		participants[i] = ingest.Participant{
+			PUUID:        participant.Puuid,
+			TeamID:       int32(participant.TeamID),
+			ChampionID:   int32(participant.ChampionID),
+			TeamPosition: participant.TeamPosition,
+			Win:          participant.Win,
+			Kills:        int32(participant.Kills),
+			Deaths:       int32(participant.Deaths),
+			Assists:      int32(participant.Assists),
+		}
+	}
+
+	return ingest.Match{Params: params, Participants: participants}, nil
+}
+
+func matchParams(match *Match) (db.CreateMatchParams, error) {
 	gameStart := pgtype.Timestamp{}
 	err := gameStart.Scan(time.Unix(match.Info.GameStartTimestamp/1000, 0)) // Note: Divided by 1000 to convert milliseconds to seconds
 	if err != nil {
-		return fmt.Errorf("error scanning game start time: %w", err)
+		return db.CreateMatchParams{}, fmt.Errorf("error scanning game start time: %w", err)
 	}
+	outcome := matchOutcome(match)
 	winningTeam, err := getWinningTeam(match)
 	if err != nil {
-		return err
+		// Aborted games are saved so they are counted, stats never use them
+		if outcome != "aborted" {
+			return db.CreateMatchParams{}, err
+		}
+		winningTeam = "none"
 	}
-	createMatchParams := db.CreateMatchParams{
+	return db.CreateMatchParams{
 		MatchID:         match.Metadata.MatchID,
 		GameStart:       gameStart,
 		GameVersion:     match.Info.GameVersion,
@@ -130,14 +260,39 @@ func saveMatch(queries *db.Queries, match *Match) error {
 		Red3ChampionID:  championID(match, 200, 3),
 		Red4ChampionID:  championID(match, 200, 4),
 		Red5ChampionID:  championID(match, 200, 5),
-	}
+		GameDuration:    pgtype.Int4{Int32: int32(gameDuration(match).Seconds()), Valid: match.Info.GameDuration > 0},
+		Outcome:         outcome,
+	}, nil
+}
 
-	err = queries.CreateMatch(context.Background(), createMatchParams)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error saving match: %v\n", err)
+// Games shorter than this can only have ended in a remake
+const remakeDuration = 5 * time.Minute
+
+// gameDuration is in seconds, or milliseconds for matches from before gameEndTimestamp was added
+func gameDuration(match *Match) time.Duration {
+	if match.Info.GameEndTimestamp == 0 {
+		return time.Duration(match.Info.GameDuration) * time.Millisecond
 	}
+	return time.Duration(match.Info.GameDuration) * time.Second
+}
 
-	return nil
+// matchOutcome tells completed games from remakes and games that were aborted without a winner
+func matchOutcome(match *Match) string {
+	if strings.HasPrefix(match.Info.EndOfGameResult, "Abort") {
+		return "aborted"
+	}
+	for _, participant := range match.Info.Participants {
+		if participant.GameEndedInEarlySurrender {
+			return "remake"
+		}
+	}
+	if match.Info.GameDuration > 0 && gameDuration(match) < remakeDuration {
+		return "remake"
+	}
+	if _, err := getWinningTeam(match); err != nil {
+		return "aborted"
+	}
+	return "completed"
 }
 
 // Helper function to get champion information
@@ -167,96 +322,79 @@ func getWinningTeam(match *Match) (string, error) {
 	return "", fmt.Errorf("no winning team found for match: %s, end of game result: %s", match.Metadata.MatchID, match.Info.EndOfGameResult)
 }
 
+// recentMatches asks for the player's recent matches in each configured queue, match-v5 only filters by one at a time
 func (c *Crawler) recentMatches(puuid string) ([]string, error) {
-	body, err := c.Client.RecentMatches(puuid, 20)
-	fmt.Println(string(body))
-	if err != nil {
-		return nil, err
-	}
-
 	var matchIDs []string
-	if err := json.Unmarshal(body, &matchIDs); err != nil {
-		return nil, fmt.Errorf("error unmarshalling match IDs: %w", err)
+	for _, queue := range c.Config.Queues {
+		body, err := c.Client.RecentMatches(puuid, api.MatchQuery{Count: c.Config.MatchCount, Queue: queue})
+		if err != nil {
+			return nil, err
+		}
+
+		var queueMatchIDs []string
+		if err := json.Unmarshal(body, &queueMatchIDs); err != nil {
+			return nil, fmt.Errorf("error unmarshalling match IDs: %w", err)
+		}
+		matchIDs = append(matchIDs, queueMatchIDs...)
 	}
 
 	return matchIDs, nil
 }
 
 func (c *Crawler) createMatch(matchID string) error {
-	// Check if match already exists
-	matchExists, err := c.Queries.MatchExists(c.Ctx, matchID)
-	if err != nil {
-		return fmt.Errorf("error checking if match exists: %w", err)
-	}
-	if matchExists {
-		fmt.Println("Match already exists", matchID)
-		return nil
-	}
-
 	matchData, err := c.Client.MatchDetails(matchID)
 	if err != nil {
 		return err
 	}
 
-	var match Match
-	if err := json.Unmarshal(matchData, &match); err != nil {
-		return fmt.Errorf("error unmarshalling match data: %w", err)
-	}
-
-	if match.Info.QueueID == 1700 {
-		fmt.Println("Skipping ranked arena match", matchID)
-		return nil
-	}
-
-	err = saveMatch(c.Queries, &match)
+	match, err := BuildMatch(matchData)
 	if err != nil {
-		return fmt.Errorf("error saving match: %w", err)
+		return err
 	}
-	fmt.Println("Created match", matchID)
 
-	return nil
-}
-
-func (c *Crawler) seedAccount() (SeedAccount, error) {
-	data, err := os.ReadFile("config/seed_accounts.json")
+	match.Raw, err = ingest.Compress(matchData)
 	if err != nil {
-		return SeedAccount{}, fmt.Errorf("error reading seed accounts: %v", err)
-	}
-
-	var seedAccounts map[string]SeedAccount
-	if err := json.Unmarshal(data, &seedAccounts); err != nil {
-		return SeedAccount{}, fmt.Errorf("error unmarshalling seed accounts: %v", err)
+		return err
 	}
 
-	if _, ok := seedAccounts[c.Client.Region]; !ok {
-		return SeedAccount{}, fmt.Errorf("no seed account found for region: %v", c.Client.Region)
+	if c.Ranks != nil {
+		puuids := make([]string, len(match.Participants))
+		for i, participant := range match.Participants {
+			puuids[i] = participant.PUUID
+		}
+		// Saved without a rank rather than dropped, it only leaves the match out of rank brackets
+		match.Params.AverageRank, err = c.Ranks.Average(c.Ctx, match.Params.ServerID, puuids)
+		if errors.Is(err, rank.ErrOverBudget) {
+			c.logger().Debug("Rank budget used up, saving without ranks", "match_id", matchID)
+		} else if err != nil {
+			c.logger().Warn("Error getting ranks, saving without them", "match_id", matchID, "err", err)
+		}
 	}
 
-	return seedAccounts[c.Client.Region], nil
-}
-
-func (c *Crawler) shouldSearch(puuid string) (bool, error) {
-	has_been_searched, err := c.Queries.PlayerHasBeenSearched(c.Ctx, puuid)
-	if err != nil {
-		return false, fmt.Errorf("error checking if player has been searched: %v", err)
-	}
-	if !has_been_searched {
-		return true, nil
+	if c.TimelineBudget != nil && c.TimelineBudget.Allow() {
+		// The match is still worth saving without its timeline
+		timelineData, err := c.Client.MatchTimeline(matchID)
+		if err == nil {
+			err = AddTimeline(&match, timelineData)
+		}
+		if err != nil {
+			c.logger().Warn("Error getting timeline, saving without it", "match_id", matchID, "err", err)
+		}
 	}
 
-	last_searched, err := c.Queries.LastSearched(c.Ctx, puuid)
-	if err != nil {
-		return false, fmt.Errorf("error getting last searched: %v", err)
-	}
+	c.Writer.Add(c.Ctx, match)
+	c.logger().Debug("Queued match", "match_id", matchID)
 
-	fmt.Printf("PUUID: %s, Last searched: %s\n", puuid, last_searched.Time)
+	return nil
+}
 
-	// Search puuid if it's been longer than 48 hours since last search
-	if time.Since(last_searched.Time) > 48*time.Hour {
-		return true, nil
+func (c *Crawler) seedAccount() (config.SeedAccount, error) {
+	seedAccount, ok := c.Config.SeedAccounts[c.Client.Region]
+	if !ok {
+		return config.SeedAccount{}, fmt.Errorf("no seed account found for region: %v", c.Client.Region)
 	}
 
-	return false, nil
+	return seedAccount, nil
 }
 
 func (c *Crawler) extractPUUIDsFromMatch(match_id string) ([]string, error) {
@@ -281,6 +419,10 @@ func (c *Crawler) extractPUUIDsFromMatch(match_id string) ([]string, error) {
 // This is a little confusing, because we are passing regions, but currently each region has one server
 // and the server is what is stored in the matches table. So for a given region we will find the relevant
 // server via the seed accounts, and then see if there are any matches from that server.
+//
+// In ladder mode players come from the server's ladder first, and the crawled matches are only used once
+// everyone on it has been searched recently. From crawled matches, players in the configured tier range are
+// picked over players whose rank is unknown, who are picked over players outside it.
 func (c *Crawler) findNextPlayer() (string, error) {
 	seedAccount, err := c.seedAccount()
 	if err != nil {
@@ -288,15 +430,33 @@ func (c *Crawler) findNextPlayer() (string, error) {
 	}
 	server := seedAccount.Server
 
+	bracket, err := c.Config.Bracket()
+	if err != nil {
+		return "", err
+	}
+
+	if c.Config.SeedMode == "ladder" {
+		puuid, err := c.nextLadderPlayer(server, bracket)
+		if err != nil {
+			c.logger().Warn("Error reading ladder, using crawled matches instead", "err", err)
+		}
+		if puuid != "" {
+			return puuid, nil
+		}
+	}
+
 	any_matches, err := c.Queries.AnyMatchesFromServer(c.Ctx, server)
 	if err != nil {
 		return "", fmt.Errorf("error checking if any matches found for server: %v", err)
 	}
 	if !any_matches {
-		fmt.Println("No matches found for server", server)
+		c.logger().Info("No matches found for server, starting from the seed account", "server", server)
 		return seedAccount.PUUID, nil
 	}
 
+	openBracket := !bracket.Min.Valid && !bracket.Max.Valid
+	var unknownRank, outsideBracket string
+	matchesSinceFallback := 0
 	i := 0
 	for i < 100 {
 		match_id, err := c.Queries.RandomMatchIDFromServer(c.Ctx, server)
@@ -314,13 +474,97 @@ func (c *Crawler) findNextPlayer() (string, error) {
 			if err != nil {
 				return "", fmt.Errorf("error checking if player should be searched: %v", err)
 			}
-			if should_search {
+			if !should_search {
+				continue
+			}
+			if openBracket {
 				return puuid, nil
 			}
+
+			score, known, err := c.playerRank(server, puuid)
+			if err != nil {
+				c.logger().Warn("Error getting rank", "puuid", puuid, "err", err)
+			}
+			switch {
+			case score.Valid && bracket.Contains(score.Int32):
+				return puuid, nil
+			case !known && unknownRank == "":
+				unknownRank = puuid
+			case known && outsideBracket == "":
+				outsideBracket = puuid
+			}
 		}
 
+		// Every lookup costs requests, so don't keep looking for long once there is someone to fall back to
+		if unknownRank != "" || outsideBracket != "" {
+			matchesSinceFallback++
+			if matchesSinceFallback >= fallbackMatches {
+				break
+			}
+		}
 		i++
 	}
 
+	if unknownRank != "" {
+		return unknownRank, nil
+	}
+	if outsideBracket != "" {
+		return outsideBracket, nil
+	}
 	return "", fmt.Errorf("no new players found")
 }
+
+// How many more matches findNextPlayer looks through for a player in the tier range after finding one outside it
+const fallbackMatches = 10
+
+// nextLadderPlayer returns the next player on the ladder who should be searched,
+// or an empty string once the whole ladder in the bracket has been gone through
+func (c *Crawler) nextLadderPlayer(server string, bracket rank.Bracket) (string, error) {
+	if c.ladder == nil {
+		ranks := c.Ranks
+		if ranks == nil {
+			// Only used to remember ladder ranks, which needs no lookups
+			ranks = &rank.Cache{Queries: c.Queries, Client: c.Client}
+		}
+		c.ladder = &Ladder{Client: c.Client, Server: server, Bracket: bracket, Ranks: ranks}
+	}
+
+	for {
+		puuid, ok, err := c.ladder.Next(c.Ctx)
+		if err != nil || !ok {
+			return "", err
+		}
+		should_search, err := c.shouldSearch(puuid)
+		if err != nil {
+			return "", fmt.Errorf("error checking if player should be searched: %v", err)
+		}
+		if should_search {
+			return puuid, nil
+		}
+	}
+}
+
+// playerRank is the player's rank score, invalid if they are unranked, and whether it is known at all.
+// It is looked up through Ranks when ranks are being fetched, otherwise only ranks already in player_ranks are known.
+// A rank the budget has no lookup left for is unknown.
+func (c *Crawler) playerRank(server, puuid string) (pgtype.Int4, bool, error) {
+	if c.Ranks != nil {
+		score, ranked, err := c.Ranks.Score(c.Ctx, server, puuid)
+		if errors.Is(err, rank.ErrOverBudget) {
+			return pgtype.Int4{}, false, nil
+		}
+		if err != nil {
+			return pgtype.Int4{}, false, err
+		}
+		return pgtype.Int4{Int32: score, Valid: ranked}, true, nil
+	}
+
+	cached, err := c.Queries.PlayerRank(c.Ctx, puuid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return pgtype.Int4{}, false, nil
+	}
+	if err != nil {
+		return pgtype.Int4{}, false, fmt.Errorf("error getting cached rank: %w", err)
+	}
+	return cached.RankScore, true, nil
+}
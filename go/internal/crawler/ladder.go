@@ -0,0 +1,89 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"lol-champ-recommender/internal/api"
+	"lol-champ-recommender/internal/rank"
+	"sort"
+)
+
+// Ladder hands out the players of a server's solo queue ladder from the top of the bracket down,
+// fetching one league list or page of entries at a time
+type Ladder struct {
+	Client  *api.RiotClient
+	Server  string
+	Bracket rank.Bracket
+	// Every listed player's rank is remembered here, so the frontier knows it without a lookup
+	Ranks *rank.Cache
+
+	ranks  []rank.Rank
+	next   int
+	page   int
+	puuids []string
+}
+
+// Next returns the next player, or false once every rank in the bracket has been listed.
+// The following call starts again from the top.
+func (l *Ladder) Next(ctx context.Context) (string, bool, error) {
+	for len(l.puuids) == 0 {
+		if l.ranks == nil {
+			l.ranks = l.Bracket.Ranks()
+			l.next = 0
+			l.page = 1
+		}
+		if l.next >= len(l.ranks) {
+			l.ranks = nil
+			return "", false, nil
+		}
+
+		current := l.ranks[l.next]
+		if current.Division == "" {
+			list, err := l.Client.ApexLeague(l.Server, current.Tier)
+			if err != nil {
+				return "", false, fmt.Errorf("error getting %s league: %w", current.Tier, err)
+			}
+			// Highest LP first, the list isn't ordered
+			sort.Slice(list.Entries, func(i, j int) bool {
+				return list.Entries[i].LeaguePoints > list.Entries[j].LeaguePoints
+			})
+			for _, entry := range list.Entries {
+				entry.Tier = list.Tier
+				l.add(ctx, entry)
+			}
+			l.next++
+			continue
+		}
+
+		entries, err := l.Client.TierEntries(l.Server, current.Tier, current.Division, l.page)
+		if err != nil {
+			return "", false, fmt.Errorf("error getting %s %s page %d: %w", current.Tier, current.Division, l.page, err)
+		}
+		if len(entries) == 0 {
+			l.next++
+			l.page = 1
+			continue
+		}
+		for _, entry := range entries {
+			l.add(ctx, entry)
+		}
+		l.page++
+	}
+
+	puuid := l.puuids[0]
+	l.puuids = l.puuids[1:]
+	return puuid, true, nil
+}
+
+func (l *Ladder) add(ctx context.Context, entry api.LeagueEntry) {
+	// Entries from before league-v4 returned puuids can't be crawled
+	if entry.PUUID == "" {
+		return
+	}
+	l.puuids = append(l.puuids, entry.PUUID)
+
+	if _, err := l.Ranks.Remember(ctx, l.Server, entry.PUUID, entry); err != nil {
+		slog.Warn("Error remembering ladder rank", "server", l.Server, "puuid", entry.PUUID, "err", err)
+	}
+}
@@ -0,0 +1,68 @@
+package crawler
+
+import (
+	"errors"
+	"fmt"
+	"lol-champ-recommender/db"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// shouldSearch reports whether the player is due to be crawled, players never crawled before always are
+func (c *Crawler) shouldSearch(puuid string) (bool, error) {
+	schedule, err := c.Queries.PlayerSchedule(c.Ctx, puuid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error getting player schedule: %w", err)
+	}
+
+	return time.Now().UTC().After(schedule.NextCrawlAt.Time), nil
+}
+
+// scheduleNext stores when the player may be crawled again, given how many new matches this crawl found
+func (c *Crawler) scheduleNext(puuid string, newMatches int) error {
+	var previous *db.PlayerSchedule
+	schedule, err := c.Queries.PlayerSchedule(c.Ctx, puuid)
+	if err == nil {
+		previous = &schedule
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("error getting player schedule: %w", err)
+	}
+
+	now := time.Now().UTC()
+	interval := c.nextInterval(previous, newMatches, now)
+	err = c.Queries.UpsertPlayerSchedule(c.Ctx, db.UpsertPlayerScheduleParams{
+		Puuid:           puuid,
+		LastCrawledAt:   pgtype.Timestamp{Time: now, Valid: true},
+		NextCrawlAt:     pgtype.Timestamp{Time: now.Add(interval), Valid: true},
+		IntervalSeconds: int32(interval.Seconds()),
+		NewMatches:      int32(newMatches),
+	})
+	if err != nil {
+		return fmt.Errorf("error saving player schedule: %w", err)
+	}
+
+	c.logger().Info("Scheduled player", "puuid", puuid, "new_matches", newMatches, "interval", interval)
+	return nil
+}
+
+// nextInterval aims to come back once the player has played about as many new matches as a crawl fetches,
+// MatchCount from each queue, going by how fast they played the ones this crawl found. Every crawl that finds nothing doubles the wait instead.
+// The first crawl of a player waits RecrawlAfter, and every wait is kept between RecrawlMin and RecrawlMax.
+func (c *Crawler) nextInterval(previous *db.PlayerSchedule, newMatches int, now time.Time) time.Duration {
+	interval := c.Config.RecrawlAfter
+	switch {
+	case previous == nil:
+	case newMatches == 0:
+		interval = 2 * time.Duration(previous.IntervalSeconds) * time.Second
+	default:
+		perMatch := now.Sub(previous.LastCrawledAt.Time) / time.Duration(newMatches)
+		interval = perMatch * time.Duration(c.Config.MatchCount*len(c.Config.Queues))
+	}
+
+	return min(max(interval, c.Config.RecrawlMin), c.Config.RecrawlMax)
+}
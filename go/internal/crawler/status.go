@@ -0,0 +1,72 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"lol-champ-recommender/db"
+	"lol-champ-recommender/internal/version"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Status is a snapshot of what has been crawled so far, the match counts are by server
+type Status struct {
+	Servers []db.MatchCountsByServerRow
+	// Newest patch first within each server
+	Patches []db.MatchCountsByPatchRow
+	// Most played queue first within each server
+	Queues []db.MatchCountsByQueueRow
+	// Distinct players crawled at least once
+	Players int64
+	// Crawled players whose next crawl is due
+	DuePlayers int64
+}
+
+// CrawlStatus reads the crawl's status from the database
+func CrawlStatus(ctx context.Context, queries *db.Queries) (Status, error) {
+	var status Status
+	var err error
+
+	status.Servers, err = queries.MatchCountsByServer(ctx)
+	if err != nil {
+		return Status{}, fmt.Errorf("error counting matches by server: %w", err)
+	}
+	status.Patches, err = queries.MatchCountsByPatch(ctx)
+	if err != nil {
+		return Status{}, fmt.Errorf("error counting matches by patch: %w", err)
+	}
+	status.Queues, err = queries.MatchCountsByQueue(ctx)
+	if err != nil {
+		return Status{}, fmt.Errorf("error counting matches by queue: %w", err)
+	}
+	status.Players, err = queries.CountSearchedPlayers(ctx)
+	if err != nil {
+		return Status{}, fmt.Errorf("error counting crawled players: %w", err)
+	}
+	status.DuePlayers, err = queries.CountDuePlayers(ctx, pgtype.Timestamp{Time: time.Now().UTC(), Valid: true})
+	if err != nil {
+		return Status{}, fmt.Errorf("error counting due players: %w", err)
+	}
+
+	// Patches sort as text in the database, which puts 14.9 after 14.20
+	sort.SliceStable(status.Patches, func(i, j int) bool {
+		a, b := status.Patches[i], status.Patches[j]
+		if a.ServerID != b.ServerID {
+			return a.ServerID < b.ServerID
+		}
+		return patchNewer(a.Patch, b.Patch)
+	})
+	return status, nil
+}
+
+// patchNewer compares patches like 14.20, ones that don't parse go last
+func patchNewer(a, b string) bool {
+	aVersion, aErr := version.Parse(a + ".0.0")
+	bVersion, bErr := version.Parse(b + ".0.0")
+	if aErr != nil || bErr != nil {
+		return aErr == nil
+	}
+	return aVersion.IsNewerThan(bVersion)
+}
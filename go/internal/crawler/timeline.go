@@ -0,0 +1,132 @@
+package crawler
+
+import (
+	"encoding/json"
+	"fmt"
+	"lol-champ-recommender/internal/ingest"
+	"strconv"
+)
+
+type Timeline struct {
+	Metadata struct {
+		MatchID string `json:"matchId"`
+	} `json:"metadata"`
+	Info struct {
+		FrameInterval int64           `json:"frameInterval"`
+		Frames        []TimelineFrame `json:"frames"`
+	} `json:"info"`
+}
+
+type TimelineFrame struct {
+	Timestamp int64 `json:"timestamp"`
+	// Keyed by participant id, "1" to "5" are blue and "6" to "10" are red
+	ParticipantFrames map[string]struct {
+		TotalGold int `json:"totalGold"`
+		XP        int `json:"xp"`
+	} `json:"participantFrames"`
+	Events []TimelineEvent `json:"events"`
+}
+
+type TimelineEvent struct {
+	Type      string `json:"type"`
+	Timestamp int64  `json:"timestamp"`
+	KillerID  int    `json:"killerId"`
+	VictimID  int    `json:"victimId"`
+	// For BUILDING_KILL the team that lost the building
+	TeamID int `json:"teamId"`
+	// For ELITE_MONSTER_KILL the team that took the monster
+	KillerTeamID int    `json:"killerTeamId"`
+	BuildingType string `json:"buildingType"`
+	MonsterType  string `json:"monsterType"`
+}
+
+// Objective names stored in match_objectives, keyed by the event that decides them
+var monsterObjectives = map[string]string{
+	"DRAGON":       "first_dragon",
+	"RIFTHERALD":   "first_herald",
+	"BARON_NASHOR": "first_baron",
+	"HORDE":        "first_grubs",
+}
+
+var buildingObjectives = map[string]string{
+	"TOWER_BUILDING":     "first_tower",
+	"INHIBITOR_BUILDING": "first_inhibitor",
+}
+
+// AddTimeline decodes a match-v5 timeline into per minute differentials and first objectives on match
+func AddTimeline(match *ingest.Match, timelineData []byte) error {
+	var timeline Timeline
+	if err := json.Unmarshal(timelineData, &timeline); err != nil {
+		return fmt.Errorf("error unmarshalling timeline: %w", err)
+	}
+
+	taken := make(map[string]bool)
+	addObjective := func(objective string, teamID int, timestamp int64) {
+		if taken[objective] || (teamID != 100 && teamID != 200) {
+			return
+		}
+		taken[objective] = true
+		match.Objectives = append(match.Objectives, ingest.Objective{
+			Objective:   objective,
+			TeamID:      int32(teamID),
+			TimestampMs: int32(timestamp),
+		})
+	}
+
+	for _, frame := range timeline.Info.Frames {
+		var goldDiff, xpDiff int
+		for id, participant := range frame.ParticipantFrames {
+			participantID, err := strconv.Atoi(id)
+			if err != nil {
+				return fmt.Errorf("invalid participant id %q in timeline", id)
+			}
+			if participantTeam(participantID) == 100 {
+				goldDiff += participant.TotalGold
+				xpDiff += participant.XP
+			} else {
+				goldDiff -= participant.TotalGold
+				xpDiff -= participant.XP
+			}
+		}
+		match.Frames = append(match.Frames, ingest.TimelineFrame{
+			Minute:   int32((frame.Timestamp + 30_000) / 60_000),
+			GoldDiff: int32(goldDiff),
+			XpDiff:   int32(xpDiff),
+		})
+
+		for _, event := range frame.Events {
+			switch event.Type {
+			case "CHAMPION_KILL":
+				// Executions have no killer, the victim's enemies still get first blood
+				addObjective("first_blood", otherTeam(participantTeam(event.VictimID)), event.Timestamp)
+			case "BUILDING_KILL":
+				if objective, ok := buildingObjectives[event.BuildingType]; ok {
+					addObjective(objective, otherTeam(event.TeamID), event.Timestamp)
+				}
+			case "ELITE_MONSTER_KILL":
+				if objective, ok := monsterObjectives[event.MonsterType]; ok {
+					addObjective(objective, event.KillerTeamID, event.Timestamp)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func participantTeam(participantID int) int {
+	if participantID <= 5 {
+		return 100
+	}
+	return 200
+}
+
+func otherTeam(teamID int) int {
+	switch teamID {
+	case 100:
+		return 200
+	case 200:
+		return 100
+	}
+	return 0
+}
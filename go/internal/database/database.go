@@ -2,59 +2,122 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
-	"os"
+	"io/fs"
+	"log/slog"
+	"strconv"
+	"time"
 
 	"lol-champ-recommender/db"
+	"lol-champ-recommender/internal/migrate"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
 )
 
 type DB struct {
-	Conn    *pgx.Conn
+	Pool    *pgxpool.Pool
 	Queries *db.Queries
 }
 
-// Initialize creates a database connection, initializes the schema, and returns a DB struct
-// We shouldn't be loading the .env file here.
-func Initialize(ctx context.Context) (*DB, error) {
-	err := godotenv.Load("../.env")
+type Options struct {
+	// Postgres connection string
+	DSN string
+	// Maximum number of open connections, zero uses the pgxpool default
+	MaxConns int
+	// Cancels statements that run longer than this, zero means no limit
+	StatementTimeout time.Duration
+}
+
+// LoadEnv loads a .env file from the working directory or its parent, whichever exist.
+// Variables that are already set are not overridden, and missing files are not an error.
+func LoadEnv() error {
+	for _, path := range []string{".env", "../.env"} {
+		err := godotenv.Load(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("error loading %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// Open creates a connection pool, applies any pending migrations, and returns a DB struct
+func Open(ctx context.Context, options Options) (*DB, error) {
+	database, err := Connect(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := database.Migrate(ctx)
 	if err != nil {
-		log.Fatal("Error loading .env file: ", err)
+		database.Close()
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+	for _, migration := range applied {
+		slog.Info("Applied migration", "version", migration.Version, "name", migration.Name)
 	}
 
-	connString := os.Getenv("DATABASE_URL")
-	if connString == "" {
-		return nil, fmt.Errorf("DATABASE_URL environment variable not set")
+	return database, nil
+}
+
+// Connect creates a connection pool without touching the schema
+func Connect(ctx context.Context, options Options) (*DB, error) {
+	if options.DSN == "" {
+		return nil, fmt.Errorf("database URL not set, set DATABASE_URL, LOLREC_DATABASE_URL, database.url in the config file or -set database.url=...")
 	}
 
-	// Connect to database
-	conn, err := pgx.Connect(ctx, connString)
+	config, err := pgxpool.ParseConfig(options.DSN)
 	if err != nil {
-		return nil, fmt.Errorf("unable to connect to database: %w", err)
+		return nil, fmt.Errorf("invalid database URL: %w", err)
+	}
+	if options.MaxConns > 0 {
+		config.MaxConns = int32(options.MaxConns)
+	}
+	if options.StatementTimeout > 0 {
+		config.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(options.StatementTimeout.Milliseconds(), 10)
 	}
 
-	// Initialize schema
-	schemaSQL, err := os.ReadFile("../db/schema.sql")
+	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
-		conn.Close(ctx)
-		return nil, fmt.Errorf("failed to read schema file: %w", err)
+		return nil, fmt.Errorf("unable to connect to database: %w", err)
 	}
 
-	if _, err = conn.Exec(ctx, string(schemaSQL)); err != nil {
-		conn.Close(ctx)
-		return nil, fmt.Errorf("failed to execute schema SQL: %w", err)
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("unable to connect to database: %w", err)
 	}
 
 	return &DB{
-		Conn:    conn,
-		Queries: db.New(conn),
+		Pool:    pool,
+		Queries: db.New(pool),
 	}, nil
 }
 
-// Close closes the database connection
-func (db *DB) Close(ctx context.Context) error {
-	return db.Conn.Close(ctx)
+// WithConn runs fn on a single connection from the pool, for work that needs session state like advisory locks
+func (db *DB) WithConn(ctx context.Context, fn func(conn *pgx.Conn) error) error {
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	return fn(conn.Conn())
+}
+
+// Migrate applies any pending migrations and returns the ones it applied
+func (db *DB) Migrate(ctx context.Context) ([]migrate.Migration, error) {
+	var applied []migrate.Migration
+	err := db.WithConn(ctx, func(conn *pgx.Conn) error {
+		var err error
+		applied, err = migrate.Up(ctx, conn)
+		return err
+	})
+	return applied, err
+}
+
+// Close closes every connection in the pool
+func (db *DB) Close() {
+	db.Pool.Close()
 }
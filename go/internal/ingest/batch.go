@@ -0,0 +1,432 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"lol-champ-recommender/db"
+	"lol-champ-recommender/internal/metrics"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Match is everything saved for one crawled match
+type Match struct {
+	Params       db.CreateMatchParams
+	Participants []Participant
+	// gzip compressed match-v5 response, nil when reprocessing from the archive
+	Raw []byte
+	// From the match timeline, empty when it wasn't fetched
+	Frames     []TimelineFrame
+	Objectives []Objective
+}
+
+type Participant struct {
+	PUUID        string
+	TeamID       int32
+	ChampionID   int32
+	TeamPosition string
+	Win          bool
+	Kills        int32
+	Deaths       int32
+	Assists      int32
+}
+
+// Blue minus red at the end of a minute
+type TimelineFrame struct {
+	Minute   int32
+	GoldDiff int32
+	XpDiff   int32
+}
+
+// The team that took the first of an objective, e.g. first_dragon
+type Objective struct {
+	Objective   string
+	TeamID      int32
+	TimestampMs int32
+}
+
+// Columns copied into the staging tables, in the order the rows are built in copyMatches
+var (
+	matchColumns = []string{
+		"match_id", "game_start", "game_version", "winning_team", "queue_id", "server_id",
+		"blue_1_champion_id", "blue_2_champion_id", "blue_3_champion_id", "blue_4_champion_id", "blue_5_champion_id",
+		"red_1_champion_id", "red_2_champion_id", "red_3_champion_id", "red_4_champion_id", "red_5_champion_id",
+		"average_rank", "game_duration", "outcome",
+	}
+	participantColumns = []string{
+		"match_id", "puuid", "team_id", "champion_id", "team_position", "win", "kills", "deaths", "assists",
+	}
+	rawMatchColumns  = []string{"match_id", "data"}
+	frameColumns     = []string{"match_id", "minute", "gold_diff", "xp_diff"}
+	objectiveColumns = []string{"match_id", "objective", "team_id", "timestamp_ms"}
+)
+
+// Flushes in a row a batch can fail before it is split up to find the matches the database rejects
+const maxFlushAttempts = 3
+
+// BatchWriter buffers matches and writes them with COPY instead of one INSERT per match.
+// A batch is flushed once it reaches the batch size, and by Run on every interval so slow crawls still get saved.
+// It is safe to use from several crawlers at once.
+type BatchWriter struct {
+	// Replace updates matches and participants that already exist instead of skipping them
+	Replace bool
+
+	pool     *pgxpool.Pool
+	size     int
+	interval time.Duration
+
+	// Serializes flushes so a failed batch is put back before the next one starts
+	flushMu sync.Mutex
+	mu      sync.Mutex
+	pending []Match
+	// Match ids that are buffered or being flushed, so they aren't fetched again in the meantime
+	pendingIDs map[string]bool
+	// Flushes that have failed since the last one that succeeded
+	failures int
+	// Called back once their matches are no longer pending
+	waiters []waiter
+}
+
+type waiter struct {
+	matchIDs []string
+	done     func()
+}
+
+func NewBatchWriter(pool *pgxpool.Pool, size int, interval time.Duration) *BatchWriter {
+	return &BatchWriter{
+		pool:       pool,
+		size:       size,
+		interval:   interval,
+		pendingIDs: make(map[string]bool),
+	}
+}
+
+// Add buffers a match, flushing the batch if it is full. Matches already buffered are ignored.
+// A failed flush is logged and retried later, the match stays buffered until it is written or rejected.
+func (w *BatchWriter) Add(ctx context.Context, match Match) {
+	w.mu.Lock()
+	if w.pendingIDs[match.Params.MatchID] {
+		w.mu.Unlock()
+		return
+	}
+	w.pending = append(w.pending, match)
+	w.pendingIDs[match.Params.MatchID] = true
+	metrics.PendingMatches.Set(float64(len(w.pendingIDs)))
+	full := len(w.pending) >= w.size
+	w.mu.Unlock()
+
+	if full {
+		if err := w.Flush(ctx); err != nil {
+			slog.Error("Error flushing matches, will retry", "err", err)
+		}
+	}
+}
+
+// Pending reports whether a match is waiting to be written
+func (w *BatchWriter) Pending(matchID string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.pendingIDs[matchID]
+}
+
+// AfterWrite calls done once none of the matches are waiting to be written, right away if none are.
+// Matches stop waiting when a flush writes them, finds them already saved or quarantines them. Until then
+// done isn't called, also not when the writer stops with them still buffered. done runs on the flushing goroutine.
+func (w *BatchWriter) AfterWrite(matchIDs []string, done func()) {
+	w.mu.Lock()
+	for _, matchID := range matchIDs {
+		if w.pendingIDs[matchID] {
+			w.waiters = append(w.waiters, waiter{matchIDs: matchIDs, done: done})
+			w.mu.Unlock()
+			return
+		}
+	}
+	w.mu.Unlock()
+	done()
+}
+
+// Run flushes every interval until ctx is done, then flushes whatever is left
+func (w *BatchWriter) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// ctx is already cancelled, the last flush still has to reach the database
+			return w.Flush(context.WithoutCancel(ctx))
+		case <-ticker.C:
+			if err := w.Flush(ctx); err != nil {
+				slog.Error("Error flushing matches, will retry", "err", err)
+			}
+		}
+	}
+}
+
+// Flush writes every buffered match. On failure the matches stay buffered for the next flush, until a batch
+// has failed maxFlushAttempts times. Then it is split up and written in parts, and the matches the database
+// still rejects on their own are quarantined. Errors like a lost connection keep the matches buffered instead.
+func (w *BatchWriter) Flush(ctx context.Context) error {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+
+	w.mu.Lock()
+	batch := w.pending
+	w.pending = nil
+	attempt := w.failures + 1
+	w.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	written, err := w.copyMatches(ctx, batch)
+	var kept []Match
+	var rejected int64
+	if err != nil {
+		kept = batch
+		if attempt >= maxFlushAttempts && !transient(err) {
+			slog.Warn("Batch keeps failing, writing it in parts", "matches", len(batch), "attempts", attempt, "err", err)
+			written, kept, rejected, err = w.split(ctx, batch, err)
+		}
+	}
+
+	w.mu.Lock()
+	w.pending = append(kept, w.pending...)
+	keptIDs := make(map[string]bool, len(kept))
+	for _, match := range kept {
+		keptIDs[match.Params.MatchID] = true
+	}
+	for _, match := range batch {
+		if !keptIDs[match.Params.MatchID] {
+			delete(w.pendingIDs, match.Params.MatchID)
+		}
+	}
+	metrics.PendingMatches.Set(float64(len(w.pendingIDs)))
+	metrics.MatchesWritten.Add(float64(written))
+	if err != nil {
+		w.failures = attempt
+	} else {
+		w.failures = 0
+	}
+	ready := w.readyWaiters()
+	w.mu.Unlock()
+
+	for _, done := range ready {
+		done()
+	}
+	if err != nil {
+		return err
+	}
+
+	if w.Replace {
+		slog.Info("Saved matches", "matches", written, "rejected", rejected)
+	} else {
+		slog.Info("Saved matches", "matches", written, "already_existed", int64(len(batch))-written-rejected, "rejected", rejected)
+	}
+	return nil
+}
+
+// readyWaiters removes and returns the callbacks whose matches are no longer pending, w.mu must be held
+func (w *BatchWriter) readyWaiters() []func() {
+	var ready []func()
+	waiting := w.waiters[:0]
+	for _, waiter := range w.waiters {
+		if slices.ContainsFunc(waiter.matchIDs, func(matchID string) bool { return w.pendingIDs[matchID] }) {
+			waiting = append(waiting, waiter)
+		} else {
+			ready = append(ready, waiter.done)
+		}
+	}
+	w.waiters = waiting
+	return ready
+}
+
+// split writes each half of a batch that failed with cause on its own, splitting the halves that fail again down
+// to single matches, which are quarantined. Returns how many matches were written and rejected. It stops at
+// a transient error and returns the matches it didn't get to.
+func (w *BatchWriter) split(ctx context.Context, batch []Match, cause error) (int64, []Match, int64, error) {
+	if len(batch) == 1 {
+		if err := w.reject(ctx, batch[0], cause); err != nil {
+			return 0, batch, 0, err
+		}
+		return 0, nil, 1, nil
+	}
+
+	var written, rejected int64
+	var kept []Match
+	var err error
+	for _, half := range [][]Match{batch[:len(batch)/2], batch[len(batch)/2:]} {
+		if err != nil {
+			kept = append(kept, half...)
+			continue
+		}
+		var n int64
+		n, err = w.copyMatches(ctx, half)
+		if err != nil && !transient(err) {
+			var k []Match
+			var r int64
+			n, k, r, err = w.split(ctx, half, err)
+			kept = append(kept, k...)
+			rejected += r
+		} else if err != nil {
+			kept = append(kept, half...)
+		}
+		written += n
+	}
+	return written, kept, rejected, err
+}
+
+// reject moves a match the database won't take to quarantined_matches, so it isn't fetched and rejected again
+func (w *BatchWriter) reject(ctx context.Context, match Match, cause error) error {
+	matchID := match.Params.MatchID
+	slog.Error("Database rejected match, quarantining it", "match_id", matchID, "err", cause)
+
+	data, err := json.Marshal(match.Params)
+	if err != nil {
+		slog.Error("Error encoding rejected match, dropping it", "match_id", matchID, "err", err)
+		metrics.MatchesRejected.Inc()
+		return nil
+	}
+	err = db.New(w.pool).QuarantineRejectedMatch(ctx, db.QuarantineRejectedMatchParams{
+		MatchID: matchID,
+		Reasons: []string{"rejected: " + cause.Error()},
+		Data:    data,
+	})
+	if err != nil && transient(err) {
+		return err
+	}
+	if err != nil {
+		slog.Error("Error quarantining rejected match, dropping it", "match_id", matchID, "err", err)
+	}
+	metrics.MatchesRejected.Inc()
+	return nil
+}
+
+// transient reports whether an error is about the connection or the server's state rather than the data,
+// so writing the same matches again can succeed
+func transient(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		// No response from the server, e.g. a lost connection or a cancelled ctx
+		return true
+	}
+	switch pgErr.Code[:2] {
+	// Connection exception, transaction rollback (deadlocks), insufficient resources, operator intervention
+	case "08", "40", "53", "57":
+		return true
+	}
+	return false
+}
+
+// copyMatches writes the batch to matches and every table hanging off it in one transaction.
+// Returns how many matches were written.
+func (w *BatchWriter) copyMatches(ctx context.Context, batch []Match) (int64, error) {
+	var matchRows, participantRows, rawRows, frameRows, objectiveRows [][]any
+	for _, match := range batch {
+		m := match.Params
+		matchRows = append(matchRows, []any{
+			m.MatchID, m.GameStart, m.GameVersion, m.WinningTeam, m.QueueID, m.ServerID,
+			m.Blue1ChampionID, m.Blue2ChampionID, m.Blue3ChampionID, m.Blue4ChampionID, m.Blue5ChampionID,
+			m.Red1ChampionID, m.Red2ChampionID, m.Red3ChampionID, m.Red4ChampionID, m.Red5ChampionID,
+			m.AverageRank, m.GameDuration, m.Outcome,
+		})
+		for _, p := range match.Participants {
+			participantRows = append(participantRows, []any{
+				m.MatchID, p.PUUID, p.TeamID, p.ChampionID, p.TeamPosition, p.Win, p.Kills, p.Deaths, p.Assists,
+			})
+		}
+		if match.Raw != nil {
+			rawRows = append(rawRows, []any{m.MatchID, match.Raw})
+		}
+		for _, f := range match.Frames {
+			frameRows = append(frameRows, []any{m.MatchID, f.Minute, f.GoldDiff, f.XpDiff})
+		}
+		for _, o := range match.Objectives {
+			objectiveRows = append(objectiveRows, []any{m.MatchID, o.Objective, o.TeamID, o.TimestampMs})
+		}
+	}
+
+	tx, err := w.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Matches first, participants reference them
+	written, err := copyUpsert(ctx, tx, "matches", matchColumns, []string{"match_id"}, matchRows, w.Replace)
+	if err != nil {
+		return 0, err
+	}
+	_, err = copyUpsert(ctx, tx, "participants", participantColumns, []string{"match_id", "puuid"}, participantRows, w.Replace)
+	if err != nil {
+		return 0, err
+	}
+	_, err = copyUpsert(ctx, tx, "match_timeline_frames", frameColumns, []string{"match_id", "minute"}, frameRows, w.Replace)
+	if err != nil {
+		return 0, err
+	}
+	_, err = copyUpsert(ctx, tx, "match_objectives", objectiveColumns, []string{"match_id", "objective"}, objectiveRows, w.Replace)
+	if err != nil {
+		return 0, err
+	}
+	// The archive is never replaced, it is what reprocessing reads from
+	_, err = copyUpsert(ctx, tx, "raw_matches", rawMatchColumns, []string{"match_id"}, rawRows, false)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("error committing matches: %w", err)
+	}
+	return written, nil
+}
+
+// copyUpsert copies rows into a temporary staging table shaped like table, then moves them into table.
+// Rows that conflict are skipped, or updated when replace is set. Returns how many rows were written.
+func copyUpsert(ctx context.Context, tx pgx.Tx, table string, columns []string, conflict []string, rows [][]any, replace bool) (int64, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	staging := table + "_staging"
+	columnList := strings.Join(columns, ", ")
+
+	_, err := tx.Exec(ctx, fmt.Sprintf("CREATE TEMP TABLE %s ON COMMIT DROP AS SELECT %s FROM %s WITH NO DATA", staging, columnList, table))
+	if err != nil {
+		return 0, fmt.Errorf("error creating %s: %w", staging, err)
+	}
+
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{staging}, columns, pgx.CopyFromRows(rows))
+	if err != nil {
+		return 0, fmt.Errorf("error copying %s: %w", table, err)
+	}
+
+	onConflict := "DO NOTHING"
+	if replace {
+		var updates []string
+		for _, column := range columns {
+			if !slices.Contains(conflict, column) {
+				// Keeps values the new row doesn't have, like the average rank of a reprocessed match
+				updates = append(updates, fmt.Sprintf("%s = COALESCE(EXCLUDED.%s, %s.%s)", column, column, table, column))
+			}
+		}
+		onConflict = "DO UPDATE SET " + strings.Join(updates, ", ")
+	}
+
+	result, err := tx.Exec(ctx, fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s ON CONFLICT (%s) %s",
+		table, columnList, columnList, staging, strings.Join(conflict, ", "), onConflict))
+	if err != nil {
+		return 0, fmt.Errorf("error inserting staged %s: %w", table, err)
+	}
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,204 @@
+package lcu
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	champSelectSessionPath  = "/lol-champ-select/v1/session"
+	champSelectSessionEvent = "OnJsonApiEvent_lol-champ-select_v1_session"
+
+	// WAMP 1.0 opcodes used by the client's websocket
+	wampSubscribe = 5
+	wampEvent     = 8
+)
+
+var ErrNotInChampSelect = errors.New("not in champ select")
+
+// Client talks to the League client's local API described by a lockfile
+type Client struct {
+	lockfile Lockfile
+	host     string
+	client   *http.Client
+	dialer   *websocket.Dialer
+}
+
+// SessionEvent is sent for every change to the champ select session.
+// EventType is "Create", "Update" or "Delete". Session is empty on "Delete".
+type SessionEvent struct {
+	EventType string
+	Session   Session
+}
+
+func NewClient(lockfile Lockfile) *Client {
+	// The League client serves a self-signed certificate on localhost
+	tlsConfig := &tls.Config{InsecureSkipVerify: true}
+
+	return &Client{
+		lockfile: lockfile,
+		host:     fmt.Sprintf("127.0.0.1:%d", lockfile.Port),
+		client: &http.Client{
+			Timeout:   time.Second * 10,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		},
+		dialer: &websocket.Dialer{
+			TLSClientConfig:  tlsConfig,
+			HandshakeTimeout: time.Second * 10,
+		},
+	}
+}
+
+func (c *Client) authHeader() http.Header {
+	token := base64.StdEncoding.EncodeToString([]byte("riot:" + c.lockfile.Password))
+	header := http.Header{}
+	header.Set("Authorization", "Basic "+token)
+	return header
+}
+
+// Session fetches the current champ select session.
+// Returns ErrNotInChampSelect when the player is not in champ select.
+func (c *Client) Session(ctx context.Context) (Session, error) {
+	url := fmt.Sprintf("%s://%s%s", c.lockfile.Protocol, c.host, champSelectSessionPath)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return Session{}, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header = c.authHeader()
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return Session{}, fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Session{}, fmt.Errorf("error reading response body: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return Session{}, ErrNotInChampSelect
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Session{}, fmt.Errorf("LCU request failed with status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var session Session
+	if err := json.Unmarshal(body, &session); err != nil {
+		return Session{}, fmt.Errorf("error unmarshalling session: %w", err)
+	}
+
+	return session, nil
+}
+
+// WatchSession subscribes to champ select session events and calls handle for each one
+// until the context is cancelled, the connection drops or handle returns an error.
+// If a session is already in progress it is sent first as a "Create" event.
+func (c *Client) WatchSession(ctx context.Context, handle func(SessionEvent) error) error {
+	url := fmt.Sprintf("wss://%s/", c.host)
+	if c.lockfile.Protocol == "http" {
+		url = fmt.Sprintf("ws://%s/", c.host)
+	}
+
+	conn, _, err := c.dialer.DialContext(ctx, url, c.authHeader())
+	if err != nil {
+		return fmt.Errorf("error connecting to LCU websocket: %w", err)
+	}
+	defer conn.Close()
+
+	// Unblock ReadMessage when the context is cancelled
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	if err := conn.WriteJSON([]interface{}{wampSubscribe, champSelectSessionEvent}); err != nil {
+		return fmt.Errorf("error subscribing to champ select session: %w", err)
+	}
+
+	session, err := c.Session(ctx)
+	if err == nil {
+		if err := handle(SessionEvent{EventType: "Create", Session: session}); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, ErrNotInChampSelect) {
+		return fmt.Errorf("error getting current session: %w", err)
+	}
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				return nil
+			}
+			return fmt.Errorf("error reading from LCU websocket: %w", err)
+		}
+
+		event, ok, err := parseSessionEvent(message)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		if err := handle(event); err != nil {
+			return err
+		}
+	}
+}
+
+// Events look like [8, "OnJsonApiEvent_lol-champ-select_v1_session", {"data": {...}, "eventType": "Update", "uri": "..."}]
+func parseSessionEvent(message []byte) (SessionEvent, bool, error) {
+	// The client sends an empty message to acknowledge the subscription
+	if len(message) == 0 {
+		return SessionEvent{}, false, nil
+	}
+
+	var frame []json.RawMessage
+	if err := json.Unmarshal(message, &frame); err != nil {
+		return SessionEvent{}, false, fmt.Errorf("error unmarshalling websocket message: %w", err)
+	}
+	if len(frame) != 3 {
+		return SessionEvent{}, false, nil
+	}
+
+	var opcode int
+	var topic string
+	if err := json.Unmarshal(frame[0], &opcode); err != nil || opcode != wampEvent {
+		return SessionEvent{}, false, nil
+	}
+	if err := json.Unmarshal(frame[1], &topic); err != nil || topic != champSelectSessionEvent {
+		return SessionEvent{}, false, nil
+	}
+
+	var payload struct {
+		Data      json.RawMessage `json:"data"`
+		EventType string          `json:"eventType"`
+	}
+	if err := json.Unmarshal(frame[2], &payload); err != nil {
+		return SessionEvent{}, false, fmt.Errorf("error unmarshalling event payload: %w", err)
+	}
+
+	event := SessionEvent{EventType: payload.EventType}
+	if payload.EventType != "Delete" {
+		if err := json.Unmarshal(payload.Data, &event.Session); err != nil {
+			return SessionEvent{}, false, fmt.Errorf("error unmarshalling session: %w", err)
+		}
+	}
+
+	return event, true, nil
+}
@@ -0,0 +1,211 @@
+package lcu
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"lol-champ-recommender/internal/recommender"
+)
+
+const testPassword = "replay-password"
+
+// startReplay serves the recording with a ReplayHandler and returns a client for it
+func startReplay(t *testing.T, tls bool, interval time.Duration) *Client {
+	t.Helper()
+	frames, err := LoadRecording("testdata/champ_select_session.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := NewReplayHandler(frames, testPassword, interval)
+	var server *httptest.Server
+	protocol := "http"
+	if tls {
+		server = httptest.NewTLSServer(handler)
+		protocol = "https"
+	} else {
+		server = httptest.NewServer(handler)
+	}
+	t.Cleanup(server.Close)
+
+	return NewClient(lockfileFor(t, server, testPassword, protocol))
+}
+
+func lockfileFor(t *testing.T, server *httptest.Server, password, protocol string) Lockfile {
+	t.Helper()
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := strconv.Atoi(serverURL.Port())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Lockfile{ProcessName: "LeagueClient", PID: 1, Port: port, Password: password, Protocol: protocol}
+}
+
+func TestWatchSessionReplay(t *testing.T) {
+	for _, tls := range []bool{false, true} {
+		t.Run("tls="+strconv.FormatBool(tls), func(t *testing.T) {
+			client := startReplay(t, tls, 0)
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			var events []SessionEvent
+			err := client.WatchSession(ctx, func(event SessionEvent) error {
+				events = append(events, event)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("WatchSession returned error: %v", err)
+			}
+
+			// The session may already be underway when WatchSession starts, then it comes first as a Create
+			if len(events) > 0 && events[0].EventType == "Create" {
+				events = events[1:]
+			}
+			var types []string
+			for _, event := range events {
+				types = append(types, event.EventType)
+			}
+			wantTypes := []string{"Update", "Update", "Update", "Update", "Update", "Delete"}
+			if !reflect.DeepEqual(types, wantTypes) {
+				t.Fatalf("got events %v, want %v", types, wantTypes)
+			}
+
+			want := recommender.ChampSelect{Bans: recordedBans, Allies: []int32{51, 25}, Enemies: []int32{22, 117}}
+			if got := events[4].Session.ChampSelect(); !reflect.DeepEqual(got, want) {
+				t.Errorf("last update's ChampSelect() = %+v, want %+v", got, want)
+			}
+			if got := events[5].Session; !reflect.DeepEqual(got, Session{}) {
+				t.Errorf("Delete carried session %+v, want it empty", got)
+			}
+		})
+	}
+}
+
+func TestWatchSessionHandlerError(t *testing.T) {
+	client := startReplay(t, false, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stop := errors.New("stop")
+	calls := 0
+	err := client.WatchSession(ctx, func(event SessionEvent) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("WatchSession returned %v, want the handler's error", err)
+	}
+	if calls != 1 {
+		t.Errorf("handler was called %d times, want it to stop after the first error", calls)
+	}
+}
+
+func TestWatchSessionCancel(t *testing.T) {
+	// Slow enough that the replay is still going when the context is cancelled
+	client := startReplay(t, false, time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+	go func() {
+		done <- client.WatchSession(ctx, func(event SessionEvent) error {
+			cancel()
+			return nil
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("WatchSession returned %v, want context.Canceled", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("WatchSession didn't return after its context was cancelled")
+	}
+}
+
+func TestWatchSessionWrongPassword(t *testing.T) {
+	frames, err := LoadRecording("testdata/champ_select_session.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(NewReplayHandler(frames, testPassword, 0))
+	defer server.Close()
+	client := NewClient(lockfileFor(t, server, "wrong", "http"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err = client.WatchSession(ctx, func(event SessionEvent) error {
+		t.Errorf("handler called with %s event, want none", event.EventType)
+		return nil
+	})
+	if err == nil {
+		t.Error("WatchSession with the wrong password returned no error")
+	}
+}
+
+func TestSessionNotInChampSelect(t *testing.T) {
+	// Nothing has been replayed before a websocket subscribes
+	client := startReplay(t, false, 0)
+	_, err := client.Session(context.Background())
+	if !errors.Is(err, ErrNotInChampSelect) {
+		t.Errorf("Session returned %v, want ErrNotInChampSelect", err)
+	}
+}
+
+func TestParseSessionEvent(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		wantOK    bool
+		wantType  string
+		wantErr   bool
+		wantLocal int
+	}{
+		{name: "subscription acknowledgement", message: ""},
+		{name: "other topic", message: `[8, "OnJsonApiEvent_lol-gameflow_v1_session", {"data": {}, "eventType": "Update"}]`},
+		{name: "other opcode", message: `[5, "OnJsonApiEvent_lol-champ-select_v1_session"]`},
+		{
+			name:      "update",
+			message:   `[8, "OnJsonApiEvent_lol-champ-select_v1_session", {"data": {"localPlayerCellId": 3}, "eventType": "Update", "uri": "/lol-champ-select/v1/session"}]`,
+			wantOK:    true,
+			wantType:  "Update",
+			wantLocal: 3,
+		},
+		{
+			name:     "delete",
+			message:  `[8, "OnJsonApiEvent_lol-champ-select_v1_session", {"data": null, "eventType": "Delete", "uri": "/lol-champ-select/v1/session"}]`,
+			wantOK:   true,
+			wantType: "Delete",
+		},
+		{name: "not json", message: `not json`, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			event, ok, err := parseSessionEvent([]byte(test.message))
+			if test.wantErr {
+				if err == nil {
+					t.Fatal("parseSessionEvent returned no error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSessionEvent returned error: %v", err)
+			}
+			if ok != test.wantOK {
+				t.Fatalf("parseSessionEvent ok = %v, want %v", ok, test.wantOK)
+			}
+			if event.EventType != test.wantType || event.Session.LocalPlayerCellID != test.wantLocal {
+				t.Errorf("parseSessionEvent = %+v, want %s with local cell %d", event, test.wantType, test.wantLocal)
+			}
+		})
+	}
+}
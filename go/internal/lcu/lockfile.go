@@ -0,0 +1,65 @@
+package lcu
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// The League client writes a lockfile while it is running. It contains a single line:
+// LeagueClient:<pid>:<port>:<password>:<protocol>
+type Lockfile struct {
+	ProcessName string
+	PID         int
+	Port        int
+	Password    string
+	Protocol    string
+}
+
+// DefaultLockfilePath returns where the League client writes its lockfile on a default install
+func DefaultLockfilePath() string {
+	if runtime.GOOS == "darwin" {
+		return "/Applications/League of Legends.app/Contents/LoL/lockfile"
+	}
+	return `C:\Riot Games\League of Legends\lockfile`
+}
+
+func ReadLockfile(path string) (Lockfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Lockfile{}, fmt.Errorf("error reading lockfile (is the League client running?): %w", err)
+	}
+
+	return ParseLockfile(string(data))
+}
+
+func ParseLockfile(contents string) (Lockfile, error) {
+	parts := strings.Split(strings.TrimSpace(contents), ":")
+	if len(parts) != 5 {
+		return Lockfile{}, fmt.Errorf("invalid lockfile format: %q", contents)
+	}
+
+	pid, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return Lockfile{}, fmt.Errorf("invalid pid in lockfile: %s", parts[1])
+	}
+
+	port, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return Lockfile{}, fmt.Errorf("invalid port in lockfile: %s", parts[2])
+	}
+
+	return Lockfile{
+		ProcessName: parts[0],
+		PID:         pid,
+		Port:        port,
+		Password:    parts[3],
+		Protocol:    parts[4],
+	}, nil
+}
+
+func (l Lockfile) String() string {
+	return fmt.Sprintf("%s:%d:%d:%s:%s", l.ProcessName, l.PID, l.Port, l.Password, l.Protocol)
+}
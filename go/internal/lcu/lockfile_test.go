@@ -0,0 +1,78 @@
+package lcu
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseLockfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     Lockfile
+		wantErr  bool
+	}{
+		{
+			name:     "valid",
+			contents: "LeagueClient:12345:54321:s3cr3t-Pa55:https",
+			want:     Lockfile{ProcessName: "LeagueClient", PID: 12345, Port: 54321, Password: "s3cr3t-Pa55", Protocol: "https"},
+		},
+		{
+			name:     "trailing newline",
+			contents: "LeagueClient:1:2999:pw:http\n",
+			want:     Lockfile{ProcessName: "LeagueClient", PID: 1, Port: 2999, Password: "pw", Protocol: "http"},
+		},
+		{name: "empty", contents: "", wantErr: true},
+		{name: "too few fields", contents: "LeagueClient:12345:54321:pw", wantErr: true},
+		{name: "too many fields", contents: "LeagueClient:12345:54321:pw:https:extra", wantErr: true},
+		{name: "pid not a number", contents: "LeagueClient:abc:54321:pw:https", wantErr: true},
+		{name: "port not a number", contents: "LeagueClient:12345:port:pw:https", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseLockfile(test.contents)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("ParseLockfile(%q) = %+v, want an error", test.contents, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseLockfile(%q) returned error: %v", test.contents, err)
+			}
+			if got != test.want {
+				t.Errorf("ParseLockfile(%q) = %+v, want %+v", test.contents, got, test.want)
+			}
+		})
+	}
+}
+
+func TestLockfileStringRoundTrips(t *testing.T) {
+	lockfile := Lockfile{ProcessName: "LeagueClient", PID: 42, Port: 60000, Password: "pw", Protocol: "https"}
+	got, err := ParseLockfile(lockfile.String())
+	if err != nil {
+		t.Fatalf("ParseLockfile(%q) returned error: %v", lockfile.String(), err)
+	}
+	if got != lockfile {
+		t.Errorf("ParseLockfile(%q) = %+v, want %+v", lockfile.String(), got, lockfile)
+	}
+}
+
+func TestReadLockfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lockfile")
+	if err := os.WriteFile(path, []byte("LeagueClient:7:8:pw:https"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadLockfile(path)
+	if err != nil {
+		t.Fatalf("ReadLockfile returned error: %v", err)
+	}
+	if got.Port != 8 || got.Password != "pw" {
+		t.Errorf("ReadLockfile = %+v, want port 8 and password pw", got)
+	}
+
+	if _, err := ReadLockfile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("ReadLockfile of a missing file returned no error")
+	}
+}
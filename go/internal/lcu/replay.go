@@ -0,0 +1,137 @@
+package lcu
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ReplayHandler is a stand-in for the League client that replays recorded champ select sessions.
+// It serves the latest frame on the session endpoint and pushes each frame as an "Update" event
+// to websocket subscribers, followed by a "Delete" once the recording is over.
+type ReplayHandler struct {
+	frames   []json.RawMessage
+	password string
+	interval time.Duration
+	upgrader websocket.Upgrader
+
+	mu      sync.Mutex
+	current int
+}
+
+// LoadRecording reads a recording, which is a JSON array of session payloads in the order they were received
+func LoadRecording(path string) ([]json.RawMessage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading recording: %w", err)
+	}
+
+	var frames []json.RawMessage
+	if err := json.Unmarshal(data, &frames); err != nil {
+		return nil, fmt.Errorf("error unmarshalling recording: %w", err)
+	}
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("recording %s has no frames", path)
+	}
+
+	return frames, nil
+}
+
+func NewReplayHandler(frames []json.RawMessage, password string, interval time.Duration) *ReplayHandler {
+	return &ReplayHandler{
+		frames:   frames,
+		password: password,
+		interval: interval,
+		current:  -1,
+	}
+}
+
+func (h *ReplayHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case websocket.IsWebSocketUpgrade(r):
+		h.serveWebsocket(w, r)
+	case r.URL.Path == champSelectSessionPath:
+		h.serveSession(w)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (h *ReplayHandler) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Basic ")
+	if !ok {
+		return false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(token)
+	return err == nil && string(decoded) == "riot:"+h.password
+}
+
+func (h *ReplayHandler) serveSession(w http.ResponseWriter) {
+	h.mu.Lock()
+	current := h.current
+	h.mu.Unlock()
+
+	if current < 0 || current >= len(h.frames) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(h.frames[current])
+}
+
+func (h *ReplayHandler) serveWebsocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	// Wait for the subscription before replaying
+	var subscription []interface{}
+	if err := conn.ReadJSON(&subscription); err != nil {
+		return
+	}
+
+	for i, frame := range h.frames {
+		h.mu.Lock()
+		h.current = i
+		h.mu.Unlock()
+
+		if err := writeEvent(conn, "Update", frame); err != nil {
+			return
+		}
+		time.Sleep(h.interval)
+	}
+
+	h.mu.Lock()
+	h.current = len(h.frames)
+	h.mu.Unlock()
+
+	writeEvent(conn, "Delete", nil)
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+}
+
+func writeEvent(conn *websocket.Conn, eventType string, data json.RawMessage) error {
+	if data == nil {
+		data = json.RawMessage("null")
+	}
+	payload := map[string]interface{}{
+		"data":      data,
+		"eventType": eventType,
+		"uri":       champSelectSessionPath,
+	}
+	return conn.WriteJSON([]interface{}{wampEvent, champSelectSessionEvent, payload})
+}
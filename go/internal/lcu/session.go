@@ -0,0 +1,138 @@
+package lcu
+
+import (
+	"lol-champ-recommender/internal/recommender"
+)
+
+// Subset of the /lol-champ-select/v1/session payload that we need to build a ChampSelect
+type Session struct {
+	LocalPlayerCellID int          `json:"localPlayerCellId"`
+	MyTeam            []TeamMember `json:"myTeam"`
+	TheirTeam         []TeamMember `json:"theirTeam"`
+	Actions           [][]Action   `json:"actions"`
+	Bans              SessionBans  `json:"bans"`
+	Timer             SessionTimer `json:"timer"`
+	// ARAM and other random pick modes have a shared bench of rerolled champions instead of pick actions
+	BenchEnabled   bool            `json:"benchEnabled"`
+	BenchChampions []BenchChampion `json:"benchChampions"`
+}
+
+type BenchChampion struct {
+	ChampionID int `json:"championId"`
+}
+
+type TeamMember struct {
+	CellID             int `json:"cellId"`
+	ChampionID         int `json:"championId"`
+	ChampionPickIntent int `json:"championPickIntent"`
+	Team               int `json:"team"`
+}
+
+// Action types are "ban", "pick" and "ten_bans_reveal"
+type Action struct {
+	ID           int    `json:"id"`
+	ActorCellID  int    `json:"actorCellId"`
+	ChampionID   int    `json:"championId"`
+	Completed    bool   `json:"completed"`
+	IsAllyAction bool   `json:"isAllyAction"`
+	IsInProgress bool   `json:"isInProgress"`
+	Type         string `json:"type"`
+}
+
+type SessionBans struct {
+	MyTeamBans    []int `json:"myTeamBans"`
+	TheirTeamBans []int `json:"theirTeamBans"`
+	NumBans       int   `json:"numBans"`
+}
+
+type SessionTimer struct {
+	Phase string `json:"phase"`
+}
+
+// ChampSelect maps the session to the recommender's view of champ select.
+// Only completed actions count, hovers are ignored. The local player is left out of Allies
+// since they are the one picking.
+func (s Session) ChampSelect() recommender.ChampSelect {
+	champSelect := recommender.ChampSelect{
+		Bans:    []int32{},
+		Allies:  []int32{},
+		Enemies: []int32{},
+	}
+
+	for _, turn := range s.Actions {
+		for _, action := range turn {
+			if !action.Completed || action.ChampionID == 0 {
+				continue
+			}
+			championID := int32(action.ChampionID)
+
+			switch action.Type {
+			case "ban":
+				champSelect.Bans = appendUnique(champSelect.Bans, championID)
+			case "pick":
+				if !action.IsAllyAction {
+					champSelect.Enemies = appendUnique(champSelect.Enemies, championID)
+				} else if action.ActorCellID != s.LocalPlayerCellID {
+					champSelect.Allies = appendUnique(champSelect.Allies, championID)
+				}
+			}
+		}
+	}
+
+	// Bans are also reported here once they are revealed, which covers sessions without ban actions
+	for _, ban := range append(s.Bans.MyTeamBans, s.Bans.TheirTeamBans...) {
+		if ban != 0 {
+			champSelect.Bans = appendUnique(champSelect.Bans, int32(ban))
+		}
+	}
+
+	// Enemy picks are hidden in some queues until they lock in, so fall back to the team lists
+	for _, member := range s.TheirTeam {
+		if member.ChampionID != 0 {
+			champSelect.Enemies = appendUnique(champSelect.Enemies, int32(member.ChampionID))
+		}
+	}
+
+	// With a bench there are no pick actions, everyone holds the champion on their team member entry
+	if s.BenchEnabled {
+		champSelect.Bench = []int32{}
+		for _, bench := range s.BenchChampions {
+			if bench.ChampionID != 0 {
+				champSelect.Bench = appendUnique(champSelect.Bench, int32(bench.ChampionID))
+			}
+		}
+		for _, member := range s.MyTeam {
+			if member.ChampionID == 0 {
+				continue
+			}
+			if member.CellID == s.LocalPlayerCellID {
+				champSelect.Rolled = int32(member.ChampionID)
+			} else {
+				champSelect.Allies = appendUnique(champSelect.Allies, int32(member.ChampionID))
+			}
+		}
+	}
+
+	return champSelect
+}
+
+// LocalPlayerPicked returns true once the local player has locked in a champion
+func (s Session) LocalPlayerPicked() bool {
+	for _, turn := range s.Actions {
+		for _, action := range turn {
+			if action.Type == "pick" && action.ActorCellID == s.LocalPlayerCellID && action.Completed {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func appendUnique(ids []int32, id int32) []int32 {
+	for _, existing := range ids {
+		if existing == id {
+			return ids
+		}
+	}
+	return append(ids, id)
+}
@@ -0,0 +1,120 @@
+package lcu
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"lol-champ-recommender/internal/recommender"
+)
+
+// recordedBans are the bans in testdata/champ_select_session.json, two players didn't ban
+var recordedBans = []int32{63, 157, 238, 84, 1, 91, 555, 122}
+
+func loadSessions(t *testing.T) []Session {
+	t.Helper()
+	frames, err := LoadRecording("testdata/champ_select_session.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessions := make([]Session, len(frames))
+	for i, frame := range frames {
+		if err := json.Unmarshal(frame, &sessions[i]); err != nil {
+			t.Fatalf("error unmarshalling frame %d: %v", i, err)
+		}
+	}
+	return sessions
+}
+
+func TestSessionChampSelect(t *testing.T) {
+	sessions := loadSessions(t)
+	// The local player is cell 2, so their own pick never shows up as an ally
+	want := []recommender.ChampSelect{
+		{Bans: []int32{}, Allies: []int32{}, Enemies: []int32{}},
+		{Bans: recordedBans, Allies: []int32{}, Enemies: []int32{}},
+		{Bans: recordedBans, Allies: []int32{51}, Enemies: []int32{}},
+		{Bans: recordedBans, Allies: []int32{51}, Enemies: []int32{22, 117}},
+		{Bans: recordedBans, Allies: []int32{51, 25}, Enemies: []int32{22, 117}},
+	}
+	if len(sessions) != len(want) {
+		t.Fatalf("recording has %d frames, want %d", len(sessions), len(want))
+	}
+	for i, session := range sessions {
+		if got := session.ChampSelect(); !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("frame %d: ChampSelect() = %+v, want %+v", i, got, want[i])
+		}
+		if session.LocalPlayerPicked() {
+			t.Errorf("frame %d: LocalPlayerPicked() = true before cell 2 picked", i)
+		}
+	}
+}
+
+func TestSessionChampSelectIgnoresHovers(t *testing.T) {
+	session := Session{
+		LocalPlayerCellID: 0,
+		Actions: [][]Action{
+			{{ActorCellID: 1, ChampionID: 51, Type: "pick", IsAllyAction: true, IsInProgress: true}},
+			{{ActorCellID: 5, ChampionID: 22, Type: "ban", IsInProgress: true}},
+		},
+	}
+	want := recommender.ChampSelect{Bans: []int32{}, Allies: []int32{}, Enemies: []int32{}}
+	if got := session.ChampSelect(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ChampSelect() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSessionChampSelectHiddenEnemyPicks(t *testing.T) {
+	// Without enemy pick actions the enemies only show up on their team entries
+	session := Session{
+		LocalPlayerCellID: 0,
+		TheirTeam:         []TeamMember{{CellID: 5, ChampionID: 22}, {CellID: 6}, {CellID: 7, ChampionID: 117}},
+	}
+	want := []int32{22, 117}
+	if got := session.ChampSelect().Enemies; !reflect.DeepEqual(got, want) {
+		t.Errorf("ChampSelect().Enemies = %v, want %v", got, want)
+	}
+}
+
+func TestSessionChampSelectBench(t *testing.T) {
+	session := Session{
+		LocalPlayerCellID: 1,
+		BenchEnabled:      true,
+		BenchChampions:    []BenchChampion{{ChampionID: 3}, {ChampionID: 0}, {ChampionID: 67}},
+		MyTeam: []TeamMember{
+			{CellID: 0, ChampionID: 51},
+			{CellID: 1, ChampionID: 25},
+			{CellID: 2, ChampionID: 0},
+			{CellID: 3, ChampionID: 117},
+		},
+	}
+	want := recommender.ChampSelect{
+		Bans:    []int32{},
+		Allies:  []int32{51, 117},
+		Enemies: []int32{},
+		Bench:   []int32{3, 67},
+		Rolled:  25,
+	}
+	if got := session.ChampSelect(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ChampSelect() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSessionLocalPlayerPicked(t *testing.T) {
+	session := loadSessions(t)[4]
+	for _, turn := range session.Actions {
+		for i := range turn {
+			if turn[i].ActorCellID == session.LocalPlayerCellID && turn[i].Type == "pick" {
+				turn[i].ChampionID = 3
+				turn[i].Completed = true
+			}
+		}
+	}
+	if !session.LocalPlayerPicked() {
+		t.Error("LocalPlayerPicked() = false after cell 2 locked in")
+	}
+	// Our own pick is the one being recommended, it is never an ally
+	want := []int32{51, 25}
+	if got := session.ChampSelect().Allies; !reflect.DeepEqual(got, want) {
+		t.Errorf("ChampSelect().Allies = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,131 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// Levels and Formats are the names Setup accepts
+var (
+	Levels  = []string{"debug", "info", "warn", "error"}
+	Formats = []string{"text", "json"}
+)
+
+// Attribute keys whose values are always redacted, compared case insensitively
+var secretKeys = []string{"x-riot-token", "api_key", "apikey", "key", "token"}
+
+const redacted = "[REDACTED]"
+
+var (
+	secretsMu sync.RWMutex
+	secrets   []string
+)
+
+// Setup makes the default slog logger, which the log package also writes through, write to w
+// at the given level and format. Everything it logs goes through redaction.
+func Setup(w io.Writer, level, format string) error {
+	var slogLevel slog.Level
+	if err := slogLevel.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("unknown log level %q, expected one of %v", level, Levels)
+	}
+	options := &slog.HandlerOptions{Level: slogLevel}
+
+	var handler slog.Handler
+	switch format {
+	case "text":
+		handler = slog.NewTextHandler(w, options)
+	case "json":
+		handler = slog.NewJSONHandler(w, options)
+	default:
+		return fmt.Errorf("unknown log format %q, expected one of %v", format, Formats)
+	}
+
+	slog.SetDefault(slog.New(redactHandler{handler}))
+	return nil
+}
+
+// Redact registers a secret, like the Riot API key, to be replaced wherever it shows up in a log message or value
+func Redact(secret string) {
+	if secret == "" {
+		return
+	}
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	for _, s := range secrets {
+		if s == secret {
+			return
+		}
+	}
+	secrets = append(secrets, secret)
+}
+
+func redactString(value string) string {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+	for _, secret := range secrets {
+		value = strings.ReplaceAll(value, secret, redacted)
+	}
+	return value
+}
+
+func redactAttr(attr slog.Attr) slog.Attr {
+	for _, key := range secretKeys {
+		if strings.EqualFold(attr.Key, key) {
+			return slog.String(attr.Key, redacted)
+		}
+	}
+
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, redactString(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		attrs := make([]any, len(group))
+		for i, a := range group {
+			attrs[i] = redactAttr(a)
+		}
+		return slog.Group(attr.Key, attrs...)
+	case slog.KindAny:
+		// Errors and anything else printed with %v can carry a secret too, e.g. a wrapped request error
+		switch value.Any().(type) {
+		case error, fmt.Stringer:
+			return slog.String(attr.Key, redactString(fmt.Sprint(value.Any())))
+		}
+	}
+	return slog.Attr{Key: attr.Key, Value: value}
+}
+
+// redactHandler scrubs secrets from the message and every attribute before passing records on
+type redactHandler struct {
+	next slog.Handler
+}
+
+func (h redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h redactHandler) Handle(ctx context.Context, record slog.Record) error {
+	scrubbed := slog.NewRecord(record.Time, record.Level, redactString(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		scrubbed.AddAttrs(redactAttr(attr))
+		return true
+	})
+	return h.next.Handle(ctx, scrubbed)
+}
+
+func (h redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	scrubbed := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		scrubbed[i] = redactAttr(attr)
+	}
+	return redactHandler{h.next.WithAttrs(scrubbed)}
+}
+
+func (h redactHandler) WithGroup(name string) slog.Handler {
+	return redactHandler{h.next.WithGroup(name)}
+}
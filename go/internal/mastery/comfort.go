@@ -0,0 +1,142 @@
+package mastery
+
+import (
+	"encoding/json"
+	"fmt"
+	"lol-champ-recommender/internal/api"
+	"math"
+	"strings"
+)
+
+const (
+	// How much of a champion's comfort comes from mastery points, the rest comes from recent results
+	masteryShare = 0.6
+	// Recent games needed before recent results count for half of their possible weight
+	recentGamesHalfWeight = 5
+)
+
+type recentMatch struct {
+	Info struct {
+		Participants []struct {
+			Puuid      string `json:"puuid"`
+			ChampionID int32  `json:"championId"`
+			Win        bool   `json:"win"`
+		} `json:"participants"`
+	} `json:"info"`
+}
+
+type recentResults struct {
+	Wins  int
+	Games int
+}
+
+// ParseRiotID splits a Riot ID like "Name#TAG" into its game name and tag line
+func ParseRiotID(riotID string) (string, string, error) {
+	gameName, tagLine, ok := strings.Cut(riotID, "#")
+	if !ok || gameName == "" || tagLine == "" {
+		return "", "", fmt.Errorf("invalid Riot ID %q, expected Name#TAG", riotID)
+	}
+	return gameName, tagLine, nil
+}
+
+// PlayerComfort returns how comfortable a player is on each champion they have played, between 0 and 1.
+// It blends their champion mastery with their results over their last recentCount ranked matches.
+// The client's region must be the regional route for server, e.g. americas for NA1.
+func PlayerComfort(client *api.RiotClient, server string, riotID string, recentCount int) (map[int32]float64, error) {
+	gameName, tagLine, err := ParseRiotID(riotID)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := client.AccountByRiotID(gameName, tagLine)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving Riot ID %s: %w", riotID, err)
+	}
+
+	masteries, err := client.ChampionMasteries(server, account.PUUID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting champion mastery: %w", err)
+	}
+
+	results, err := recentChampionResults(client, account.PUUID, recentCount)
+	if err != nil {
+		return nil, fmt.Errorf("error getting recent matches: %w", err)
+	}
+
+	return comfortScores(masteries, results), nil
+}
+
+func recentChampionResults(client *api.RiotClient, puuid string, count int) (map[int32]recentResults, error) {
+	results := make(map[int32]recentResults)
+	if count <= 0 {
+		return results, nil
+	}
+
+	body, err := client.RecentMatches(puuid, api.MatchQuery{Count: count, Type: "ranked"})
+	if err != nil {
+		return nil, err
+	}
+
+	var matchIDs []string
+	if err := json.Unmarshal(body, &matchIDs); err != nil {
+		return nil, fmt.Errorf("error unmarshalling match IDs: %w", err)
+	}
+
+	for _, matchID := range matchIDs {
+		matchData, err := client.MatchDetails(matchID)
+		if err != nil {
+			return nil, err
+		}
+
+		var match recentMatch
+		if err := json.Unmarshal(matchData, &match); err != nil {
+			return nil, fmt.Errorf("error unmarshalling match data: %w", err)
+		}
+
+		for _, participant := range match.Info.Participants {
+			if participant.Puuid != puuid {
+				continue
+			}
+			champResults := results[participant.ChampionID]
+			champResults.Games++
+			if participant.Win {
+				champResults.Wins++
+			}
+			results[participant.ChampionID] = champResults
+		}
+	}
+
+	return results, nil
+}
+
+// Mastery points are scaled logarithmically against the player's most played champion, so a
+// one-trick's second champion still counts for something. Recent results are a smoothed winrate
+// that only counts fully once the champion has been played a few times.
+func comfortScores(masteries []api.ChampionMastery, results map[int32]recentResults) map[int32]float64 {
+	maxPoints := 0
+	for _, m := range masteries {
+		if m.ChampionPoints > maxPoints {
+			maxPoints = m.ChampionPoints
+		}
+	}
+
+	masteryComfort := make(map[int32]float64)
+	for _, m := range masteries {
+		if maxPoints > 0 {
+			masteryComfort[m.ChampionID] = math.Log1p(float64(m.ChampionPoints)) / math.Log1p(float64(maxPoints))
+		}
+	}
+
+	comfort := make(map[int32]float64)
+	for champID, value := range masteryComfort {
+		comfort[champID] = masteryShare * value
+	}
+	for champID, r := range results {
+		played := float64(r.Games) / float64(r.Games+recentGamesHalfWeight)
+		winrate := float64(r.Wins+1) / float64(r.Games+2)
+		recent := math.Min(1, played*winrate*2)
+		comfort[champID] = masteryShare*masteryComfort[champID] + (1-masteryShare)*recent
+	}
+
+	return comfort
+}
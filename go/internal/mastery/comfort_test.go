@@ -0,0 +1,239 @@
+package mastery
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"lol-champ-recommender/internal/api"
+	"lol-champ-recommender/internal/api/riottest"
+)
+
+func TestParseRiotID(t *testing.T) {
+	tests := []struct {
+		riotID   string
+		gameName string
+		tagLine  string
+		wantErr  bool
+	}{
+		{riotID: "Doublelift#NA1", gameName: "Doublelift", tagLine: "NA1"},
+		{riotID: "Hide on bush#KR1", gameName: "Hide on bush", tagLine: "KR1"},
+		// Only the first # splits, tag lines can't contain one anyway
+		{riotID: "a#b#c", gameName: "a", tagLine: "b#c"},
+		{riotID: "Doublelift", wantErr: true},
+		{riotID: "#NA1", wantErr: true},
+		{riotID: "Doublelift#", wantErr: true},
+	}
+	for _, test := range tests {
+		gameName, tagLine, err := ParseRiotID(test.riotID)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("ParseRiotID(%q) = %q, %q, want an error", test.riotID, gameName, tagLine)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseRiotID(%q) returned error: %v", test.riotID, err)
+			continue
+		}
+		if gameName != test.gameName || tagLine != test.tagLine {
+			t.Errorf("ParseRiotID(%q) = %q, %q, want %q, %q", test.riotID, gameName, tagLine, test.gameName, test.tagLine)
+		}
+	}
+}
+
+func TestComfortScores(t *testing.T) {
+	tests := []struct {
+		name      string
+		masteries []api.ChampionMastery
+		results   map[int32]recentResults
+		want      map[int32]float64
+	}{
+		{
+			name:      "no data",
+			masteries: nil,
+			results:   nil,
+			want:      map[int32]float64{},
+		},
+		{
+			name: "mastery only",
+			masteries: []api.ChampionMastery{
+				{ChampionID: 1, ChampionPoints: 10000},
+				{ChampionID: 2, ChampionPoints: 100},
+			},
+			want: map[int32]float64{
+				1: masteryShare,
+				2: masteryShare * math.Log1p(100) / math.Log1p(10000),
+			},
+		},
+		{
+			name:    "recent results only",
+			results: map[int32]recentResults{3: {Wins: 5, Games: 5}},
+			// Played 5/10 of full weight at a smoothed winrate of 6/7
+			want: map[int32]float64{3: (1 - masteryShare) * 0.5 * 6.0 / 7.0 * 2},
+		},
+		{
+			name:      "most played and winning is fully comfortable",
+			masteries: []api.ChampionMastery{{ChampionID: 1, ChampionPoints: 500000}},
+			results:   map[int32]recentResults{1: {Wins: 10, Games: 10}},
+			want:      map[int32]float64{1: 1},
+		},
+		{
+			name:      "losing adds little to mastery",
+			masteries: []api.ChampionMastery{{ChampionID: 1, ChampionPoints: 500000}},
+			results:   map[int32]recentResults{1: {Wins: 0, Games: 10}},
+			// Played 10/15 of full weight at a smoothed winrate of 1/12
+			want: map[int32]float64{1: masteryShare + (1-masteryShare)*10.0/15.0*1.0/12.0*2},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := comfortScores(test.masteries, test.results)
+			if len(got) != len(test.want) {
+				t.Fatalf("got comfort for %d champions, want %d: %v", len(got), len(test.want), got)
+			}
+			for champion, want := range test.want {
+				if math.Abs(got[champion]-want) > 1e-9 {
+					t.Errorf("comfort on %d = %f, want %f", champion, got[champion], want)
+				}
+			}
+		})
+	}
+}
+
+func TestComfortScoresBounds(t *testing.T) {
+	masteries := []api.ChampionMastery{
+		{ChampionID: 1, ChampionPoints: 1000000},
+		{ChampionID: 2, ChampionPoints: 1},
+		{ChampionID: 3, ChampionPoints: 0},
+	}
+	results := map[int32]recentResults{
+		1: {Wins: 100, Games: 100},
+		2: {Wins: 0, Games: 100},
+		4: {Wins: 1, Games: 1},
+	}
+	for champion, comfort := range comfortScores(masteries, results) {
+		if comfort < 0 || comfort > 1 {
+			t.Errorf("comfort on %d = %f, want between 0 and 1", champion, comfort)
+		}
+	}
+}
+
+func TestComfortScoresMoreGamesCountMore(t *testing.T) {
+	few := comfortScores(nil, map[int32]recentResults{1: {Wins: 2, Games: 2}})
+	many := comfortScores(nil, map[int32]recentResults{1: {Wins: 8, Games: 8}})
+	if many[1] <= few[1] {
+		t.Errorf("comfort after 8 wins = %f, want more than after 2 wins (%f)", many[1], few[1])
+	}
+}
+
+func TestPlayerComfort(t *testing.T) {
+	server := riottest.NewServer()
+	defer server.Close()
+
+	server.AddAccount(api.Account{PUUID: "player", GameName: "Player", TagLine: "NA1"})
+	masteries := []api.ChampionMastery{
+		{ChampionID: 1, ChampionLevel: 7, ChampionPoints: 200000},
+		{ChampionID: 2, ChampionLevel: 5, ChampionPoints: 30000},
+	}
+	for _, mastery := range masteries {
+		server.AddMastery("player", mastery)
+	}
+	// Oldest first, only the newest two are asked for
+	server.AddMatch("NA1_1",
+		riottest.Participant{PUUID: "player", ChampionID: 2, TeamID: 100, Win: true},
+		riottest.Participant{PUUID: "other", ChampionID: 5, TeamID: 200})
+	server.AddMatch("NA1_2",
+		riottest.Participant{PUUID: "player", ChampionID: 3, TeamID: 200},
+		riottest.Participant{PUUID: "other", ChampionID: 5, TeamID: 100, Win: true})
+	server.AddMatch("NA1_3",
+		riottest.Participant{PUUID: "other", ChampionID: 3, TeamID: 100},
+		riottest.Participant{PUUID: "player", ChampionID: 1, TeamID: 200, Win: true})
+
+	client := server.Client(context.Background(), "americas")
+	got, err := PlayerComfort(client, "NA1", "Player#NA1", 2)
+	if err != nil {
+		t.Fatalf("PlayerComfort returned error: %v", err)
+	}
+
+	want := comfortScores(masteries, map[int32]recentResults{
+		1: {Wins: 1, Games: 1},
+		3: {Wins: 0, Games: 1},
+	})
+	if len(got) != len(want) {
+		t.Fatalf("got comfort for %d champions, want %d: %v", len(got), len(want), got)
+	}
+	for champion, comfort := range want {
+		if math.Abs(got[champion]-comfort) > 1e-9 {
+			t.Errorf("comfort on %d = %f, want %f", champion, got[champion], comfort)
+		}
+	}
+
+	var paths []string
+	for _, request := range server.Requests() {
+		// Account-v1 and match-v5 are regional, champion-mastery-v4 is routed by platform
+		wantRouting := "americas"
+		if strings.Contains(request.Path, "champion-mastery") {
+			wantRouting = "na1"
+		}
+		if request.Routing != wantRouting {
+			t.Errorf("%s was routed to %s, want %s", request.Path, request.Routing, wantRouting)
+		}
+		if strings.HasSuffix(request.Path, "/ids") && request.Query != "count=2&type=ranked" {
+			t.Errorf("match ids were requested with %q, want count=2&type=ranked", request.Query)
+		}
+		paths = append(paths, request.Path)
+	}
+	wantPaths := []string{
+		"/riot/account/v1/accounts/by-riot-id/Player/NA1",
+		"/lol/champion-mastery/v4/champion-masteries/by-puuid/player",
+		"/lol/match/v5/matches/by-puuid/player/ids",
+		"/lol/match/v5/matches/NA1_3",
+		"/lol/match/v5/matches/NA1_2",
+	}
+	if strings.Join(paths, "\n") != strings.Join(wantPaths, "\n") {
+		t.Errorf("requested\n%s\nwant\n%s", strings.Join(paths, "\n"), strings.Join(wantPaths, "\n"))
+	}
+}
+
+func TestPlayerComfortWithoutRecentMatches(t *testing.T) {
+	server := riottest.NewServer()
+	defer server.Close()
+
+	server.AddAccount(api.Account{PUUID: "player", GameName: "Player", TagLine: "NA1"})
+	server.AddMastery("player", api.ChampionMastery{ChampionID: 1, ChampionPoints: 5000})
+	server.AddMatch("NA1_1", riottest.Participant{PUUID: "player", ChampionID: 2, Win: true})
+
+	client := server.Client(context.Background(), "americas")
+	got, err := PlayerComfort(client, "NA1", "Player#NA1", 0)
+	if err != nil {
+		t.Fatalf("PlayerComfort returned error: %v", err)
+	}
+	if len(got) != 1 || got[1] != masteryShare {
+		t.Errorf("PlayerComfort = %v, want only mastery comfort on 1", got)
+	}
+	for _, request := range server.Requests() {
+		if strings.Contains(request.Path, "/lol/match/") {
+			t.Errorf("requested %s, want no match-v5 requests", request.Path)
+		}
+	}
+}
+
+func TestPlayerComfortErrors(t *testing.T) {
+	server := riottest.NewServer()
+	defer server.Close()
+	client := server.Client(context.Background(), "americas")
+
+	if _, err := PlayerComfort(client, "NA1", "no tag", 20); err == nil {
+		t.Error("PlayerComfort with an invalid Riot ID returned no error")
+	}
+	if len(server.Requests()) != 0 {
+		t.Errorf("an invalid Riot ID made %d requests, want none", len(server.Requests()))
+	}
+
+	_, err := PlayerComfort(client, "NA1", "Missing#NA1", 20)
+	if err == nil || !strings.Contains(err.Error(), "Missing#NA1") {
+		t.Errorf("PlayerComfort for an unknown account returned %v, want an error naming the Riot ID", err)
+	}
+}
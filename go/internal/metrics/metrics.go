@@ -0,0 +1,177 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	// Riot API requests by endpoint, e.g. match-v5/matches, and HTTP status, "error" when no response came back
+	Requests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "lolrec_riot_requests_total",
+		Help: "Riot API requests by region, endpoint and status.",
+	}, []string{"region", "endpoint", "status"})
+	RateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "lolrec_riot_rate_limited_total",
+		Help: "Riot API responses with status 429.",
+	}, []string{"region"})
+	LimiterWait = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "lolrec_riot_limiter_wait_seconds_total",
+		Help: "Time spent waiting on the request rate limiter and Retry-After.",
+	}, []string{"region"})
+
+	// Matches a crawler came across by what happened to them: queued, duplicate or failed
+	Matches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "lolrec_crawler_matches_total",
+		Help: "Matches found by crawlers by region and result.",
+	}, []string{"region", "result"})
+	PlayersCrawled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "lolrec_crawler_players_crawled_total",
+		Help: "Players whose recent matches were crawled.",
+	}, []string{"region"})
+	// Players whose scheduled recrawl time has passed, shared by every region
+	Frontier = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "lolrec_crawler_frontier_players",
+		Help: "Crawled players that are due to be crawled again.",
+	})
+
+	// Written by the shared batch writer, so not per region
+	MatchesWritten = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "lolrec_matches_written_total",
+		Help: "Matches written to the database.",
+	})
+	// Matches the database wouldn't take even on their own, they are quarantined instead of written
+	MatchesRejected = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "lolrec_matches_rejected_total",
+		Help: "Matches the database rejected, which were quarantined.",
+	})
+	PendingMatches = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "lolrec_matches_pending",
+		Help: "Matches buffered and waiting to be written.",
+	})
+)
+
+// Serve exposes the metrics on addr at /metrics until ctx is done
+func Serve(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	server := &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.WithoutCancel(ctx))
+	}()
+
+	err := server.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// LogSummary logs what happened since the last summary every interval until ctx is done, one line per region
+// and one for the whole crawl. frontier is called first to refresh the frontier gauge.
+func LogSummary(ctx context.Context, interval time.Duration, frontier func(context.Context) (int64, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := totals()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if size, err := frontier(ctx); err == nil {
+			Frontier.Set(float64(size))
+		} else {
+			slog.Warn("Error counting due players", "err", err)
+		}
+		current := totals()
+		logSummary(last, current, interval)
+		last = current
+	}
+}
+
+func logSummary(last, current map[string]float64, interval time.Duration) {
+	delta := func(name string) int64 { return int64(current[name] - last[name]) }
+
+	var regions []string
+	for key := range current {
+		if region, ok := strings.CutPrefix(key, "requests/"); ok {
+			regions = append(regions, region)
+		}
+	}
+	sort.Strings(regions)
+
+	for _, region := range regions {
+		slog.Info("Crawl summary",
+			"region", region,
+			"interval", interval,
+			"requests", delta("requests/"+region),
+			"rate_limited", delta("rate_limited/"+region),
+			"limiter_wait", time.Duration((current["limiter_wait/"+region]-last["limiter_wait/"+region])*float64(time.Second)).Round(time.Millisecond),
+			"players", delta("players/"+region),
+			"queued", delta("queued/"+region),
+			"duplicate", delta("duplicate/"+region),
+			"failed", delta("failed/"+region))
+	}
+	slog.Info("Crawl summary",
+		"interval", interval,
+		"matches_written", delta("written"),
+		"matches_rejected", delta("rejected"),
+		"matches_pending", int64(current["pending"]),
+		"players_due", int64(current["frontier"]))
+}
+
+// totals sums the metrics by name and region, keyed like "requests/americas"
+func totals() map[string]float64 {
+	result := make(map[string]float64)
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		return result
+	}
+
+	names := map[string]string{
+		"lolrec_riot_requests_total":             "requests",
+		"lolrec_riot_rate_limited_total":         "rate_limited",
+		"lolrec_riot_limiter_wait_seconds_total": "limiter_wait",
+		"lolrec_crawler_players_crawled_total":   "players",
+		"lolrec_matches_written_total":           "written",
+		"lolrec_matches_rejected_total":          "rejected",
+		"lolrec_matches_pending":                 "pending",
+		"lolrec_crawler_frontier_players":        "frontier",
+	}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			value := metric.GetCounter().GetValue() + metric.GetGauge().GetValue()
+			labels := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+
+			key, ok := names[family.GetName()]
+			if family.GetName() == "lolrec_crawler_matches_total" {
+				key, ok = labels["result"], true
+			}
+			if !ok {
+				continue
+			}
+			if region, ok := labels["region"]; ok {
+				key += "/" + region
+			}
+			result[key] += value
+		}
+	}
+	return result
+}
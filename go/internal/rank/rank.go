@@ -0,0 +1,223 @@
+package rank
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"lol-champ-recommender/db"
+	"lol-champ-recommender/internal/api"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/time/rate"
+)
+
+// Tiers from lowest to highest, as league-v4 names them
+var Tiers = []string{"IRON", "BRONZE", "SILVER", "GOLD", "PLATINUM", "EMERALD", "DIAMOND", "MASTER", "GRANDMASTER", "CHALLENGER"}
+
+var divisions = []string{"IV", "III", "II", "I"}
+
+// Tiers from here up have no divisions
+const apexTier = "MASTER"
+
+// Score orders ranks so they can be averaged and compared: four points per tier and one per division,
+// Iron IV is 0 and Challenger is 36
+func Score(tier, division string) (int32, error) {
+	tierIndex := slices.Index(Tiers, strings.ToUpper(tier))
+	if tierIndex < 0 {
+		return 0, fmt.Errorf("unknown tier %q, expected one of %v", tier, Tiers)
+	}
+	score := int32(tierIndex * len(divisions))
+	if tierIndex >= slices.Index(Tiers, apexTier) {
+		return score, nil
+	}
+
+	divisionIndex := slices.Index(divisions, strings.ToUpper(division))
+	if divisionIndex < 0 {
+		return 0, fmt.Errorf("unknown division %q, expected one of %v", division, divisions)
+	}
+	return score + int32(divisionIndex), nil
+}
+
+// Name is the rank closest to a score, e.g. "DIAMOND II"
+func Name(score int32) string {
+	tierIndex := int(score) / len(divisions)
+	if tierIndex >= len(Tiers) {
+		tierIndex = len(Tiers) - 1
+	}
+	if tierIndex < 0 {
+		tierIndex = 0
+	}
+	if tierIndex >= slices.Index(Tiers, apexTier) {
+		return Tiers[tierIndex]
+	}
+	return Tiers[tierIndex] + " " + divisions[int(score)%len(divisions)]
+}
+
+// Bracket limits stats to matches with an average rank in a range, either end can be left open
+type Bracket struct {
+	Min pgtype.Int4
+	Max pgtype.Int4
+}
+
+// ParseBracket reads a tier range like "diamond" to "challenger". The minimum starts at the bottom division
+// of its tier and the maximum ends at the top division of its tier. Empty strings leave that end open.
+func ParseBracket(minTier, maxTier string) (Bracket, error) {
+	var bracket Bracket
+	if minTier != "" {
+		score, err := Score(minTier, divisions[0])
+		if err != nil {
+			return Bracket{}, err
+		}
+		bracket.Min = pgtype.Int4{Int32: score, Valid: true}
+	}
+	if maxTier != "" {
+		score, err := Score(maxTier, divisions[len(divisions)-1])
+		if err != nil {
+			return Bracket{}, err
+		}
+		bracket.Max = pgtype.Int4{Int32: score, Valid: true}
+	}
+	if bracket.Min.Valid && bracket.Max.Valid && bracket.Min.Int32 > bracket.Max.Int32 {
+		return Bracket{}, fmt.Errorf("minimum tier %s is above maximum tier %s", minTier, maxTier)
+	}
+	return bracket, nil
+}
+
+// Contains reports whether a score is inside the bracket
+func (b Bracket) Contains(score int32) bool {
+	return (!b.Min.Valid || score >= b.Min.Int32) && (!b.Max.Valid || score <= b.Max.Int32)
+}
+
+// Rank is a ladder position, Division is empty for the apex tiers
+type Rank struct {
+	Tier     string
+	Division string
+}
+
+// Ranks lists every rank in the bracket from the highest down, the order the ladder is seeded in
+func (b Bracket) Ranks() []Rank {
+	var ranks []Rank
+	for i := len(Tiers) - 1; i >= 0; i-- {
+		tier := Tiers[i]
+		if i >= slices.Index(Tiers, apexTier) {
+			if b.Contains(int32(i * len(divisions))) {
+				ranks = append(ranks, Rank{Tier: tier})
+			}
+			continue
+		}
+		for j := len(divisions) - 1; j >= 0; j-- {
+			if b.Contains(int32(i*len(divisions) + j)) {
+				ranks = append(ranks, Rank{Tier: tier, Division: divisions[j]})
+			}
+		}
+	}
+	return ranks
+}
+
+func (b Bracket) String() string {
+	switch {
+	case b.Min.Valid && b.Max.Valid:
+		return Name(b.Min.Int32) + " to " + Name(b.Max.Int32)
+	case b.Min.Valid:
+		return Name(b.Min.Int32) + " and above"
+	case b.Max.Valid:
+		return Name(b.Max.Int32) + " and below"
+	}
+	return "all ranks"
+}
+
+// Cache looks up solo queue ranks through player_ranks, only calling league-v4 when a player's rank is missing or older than TTL
+// ErrOverBudget is returned when a rank isn't cached and the budget allows no lookup right now
+var ErrOverBudget = errors.New("rank lookup over budget")
+
+type Cache struct {
+	Queries *db.Queries
+	Client  *api.RiotClient
+	TTL     time.Duration
+	// League-v4 lookups are only made while the budget allows when set, cached ranks are always returned
+	Budget *rate.Limiter
+}
+
+// Score returns the player's rank score, or false if they are unranked in solo queue
+func (c *Cache) Score(ctx context.Context, server, puuid string) (int32, bool, error) {
+	cached, err := c.Queries.PlayerRank(ctx, puuid)
+	if err == nil && time.Since(cached.FetchedAt.Time) < c.TTL {
+		return cached.RankScore.Int32, cached.RankScore.Valid, nil
+	}
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, fmt.Errorf("error getting cached rank: %w", err)
+	}
+	if c.Budget != nil && !c.Budget.Allow() {
+		return 0, false, ErrOverBudget
+	}
+
+	entries, err := c.Client.LeagueEntries(server, puuid)
+	if err != nil {
+		return 0, false, err
+	}
+
+	var solo *api.LeagueEntry
+	for i, entry := range entries {
+		if entry.QueueType == "RANKED_SOLO_5x5" {
+			solo = &entries[i]
+		}
+	}
+	if solo == nil {
+		err = c.Queries.UpsertPlayerRank(ctx, db.UpsertPlayerRankParams{Puuid: puuid, ServerID: server})
+		if err != nil {
+			return 0, false, fmt.Errorf("error caching rank: %w", err)
+		}
+		return 0, false, nil
+	}
+
+	score, err := c.Remember(ctx, server, puuid, *solo)
+	if err != nil {
+		return 0, false, err
+	}
+	return score, true, nil
+}
+
+// Remember caches a solo queue entry that was fetched some other way, like from the ladder, and returns its score
+func (c *Cache) Remember(ctx context.Context, server, puuid string, entry api.LeagueEntry) (int32, error) {
+	score, err := Score(entry.Tier, entry.Rank)
+	if err != nil {
+		return 0, err
+	}
+
+	err = c.Queries.UpsertPlayerRank(ctx, db.UpsertPlayerRankParams{
+		Puuid:        puuid,
+		ServerID:     server,
+		Tier:         entry.Tier,
+		Division:     entry.Rank,
+		LeaguePoints: int32(entry.LeaguePoints),
+		RankScore:    pgtype.Int4{Int32: score, Valid: true},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error caching rank: %w", err)
+	}
+	return score, nil
+}
+
+// Average is the rounded average score of the ranked players, invalid if none of them are ranked
+func (c *Cache) Average(ctx context.Context, server string, puuids []string) (pgtype.Int4, error) {
+	total, ranked := 0, 0
+	for _, puuid := range puuids {
+		score, ok, err := c.Score(ctx, server, puuid)
+		if err != nil {
+			return pgtype.Int4{}, err
+		}
+		if ok {
+			total += int(score)
+			ranked++
+		}
+	}
+
+	if ranked == 0 {
+		return pgtype.Int4{}, nil
+	}
+	return pgtype.Int4{Int32: int32((total + ranked/2) / ranked), Valid: true}, nil
+}
@@ -0,0 +1,43 @@
+package recommender
+
+import "fmt"
+
+// RecommendAram ranks the champions we can end up with in ARAM: the one we rolled and the ones on the bench.
+// Champions are scored on their synergy with our teammates, their matchups against any enemies that are known,
+// and their own ARAM winrate, so the stats should come from an ARAM snapshot.
+func RecommendAram(championStats ChampionDataMap, champSelect ChampSelect) ([]ChampionPerformance, error) {
+	candidates := append([]int32{}, champSelect.Bench...)
+	if champSelect.Rolled != 0 {
+		candidates = appendUniqueID(candidates, champSelect.Rolled)
+	}
+
+	var results []ChampionPerformance
+	for _, champID := range candidates {
+		if _, ok := championStats[champID]; !ok {
+			return nil, fmt.Errorf("no stats for champion %d, the snapshot might be older than the champion", champID)
+		}
+
+		performance, err := championPerformance(champID, championStats, champSelect)
+		if err != nil {
+			return nil, fmt.Errorf("error getting performance for champion %d: %w", champID, err)
+		}
+
+		// Without a draft the champion's own strength matters most, it counts as one more interaction
+		winrate := createInteraction(champID, championStats[champID].Winrate)
+		performance.WinProbability = calculateWinProbability(append(performance.Synergies, winrate), performance.Matchups)
+		performance.Score = performance.WinProbability
+
+		results = append(results, performance)
+	}
+
+	sortResults(results)
+
+	return results, nil
+}
+
+func appendUniqueID(ids []int32, id int32) []int32 {
+	if contains(ids, id) {
+		return ids
+	}
+	return append(ids, id)
+}
@@ -0,0 +1,43 @@
+package recommender
+
+import (
+	"sort"
+)
+
+const (
+	// Matchups with fewer games are too rare to count as a plausible counter pick
+	minCounterGames = 20
+	// How many of the worst responses are averaged, so one outlier matchup doesn't decide the score
+	worstResponses = 3
+)
+
+// counterability is how much champID's win probability drops, on average, against the enemy picks
+// that hurt it the most. A safe blind pick has a low counterability.
+func counterability(champID int32, championStats ChampionDataMap, performance ChampionPerformance, unavailable []int32) float64 {
+	var responses []float64
+	for enemyID, stats := range championStats[champID].Matchups {
+		if stats.Games < minCounterGames || enemyID == champID || contains(unavailable, enemyID) {
+			continue
+		}
+
+		matchups := append(append([]ChampionInteraction{}, performance.Matchups...), createInteraction(enemyID, stats))
+		synergies := append([]ChampionInteraction{}, performance.Synergies...)
+		responses = append(responses, calculateWinProbability(synergies, matchups))
+	}
+
+	if len(responses) == 0 {
+		return 0
+	}
+
+	sort.Float64s(responses)
+	if len(responses) > worstResponses {
+		responses = responses[:worstResponses]
+	}
+
+	total := 0.0
+	for _, response := range responses {
+		total += response
+	}
+
+	return performance.WinProbability - total/float64(len(responses))
+}
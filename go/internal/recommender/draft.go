@@ -0,0 +1,218 @@
+package recommender
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+type Side int
+
+const (
+	Blue Side = iota
+	Red
+)
+
+// Standard ranked pick order after bans: B1, R1-R2, B2-B3, R3-R4, B4-B5, R5
+var PickOrder = []Side{Blue, Red, Red, Blue, Blue, Red, Red, Blue, Blue, Red}
+
+func (s Side) String() string {
+	if s == Red {
+		return "red"
+	}
+	return "blue"
+}
+
+func ParseSide(side string) (Side, error) {
+	switch strings.ToLower(side) {
+	case "blue":
+		return Blue, nil
+	case "red":
+		return Red, nil
+	}
+	return Blue, fmt.Errorf("invalid side %q, expected blue or red", side)
+}
+
+type DraftOptions struct {
+	// Which side we are picking for
+	Side Side
+	// How many picks after ours to search
+	Depth int
+	// How many champions are considered for each searched pick before the last one
+	Breadth int
+}
+
+// draftState is the picks made so far from our point of view. Next is the index into PickOrder of the next pick.
+type draftState struct {
+	allies      []int32
+	enemies     []int32
+	unavailable map[int32]bool
+	next        int
+	// The champion being scored and the index into PickOrder of our pick, enemies can still pick before it
+	pick   int32
+	pickAt int
+}
+
+// draftWinProbability scores picking champID now by our team's expected win probability after the
+// following picks. Enemies are assumed to pick the champion that hurts us most, our teammates are
+// assumed to pick like the average player, weighted by how often each champion is played.
+// Our pick is our side's next turn in PickOrder, enemy picks that come before it are searched first.
+func draftWinProbability(championStats ChampionDataMap, champSelect ChampSelect, champID int32, options DraftOptions) (float64, error) {
+	made := len(champSelect.Allies) + len(champSelect.Enemies)
+	pickAt := -1
+	for i := made; i < len(PickOrder); i++ {
+		if PickOrder[i] == options.Side {
+			pickAt = i
+			break
+		}
+	}
+	if pickAt < 0 {
+		return 0, fmt.Errorf("no picks left for %s side in the draft", options.Side)
+	}
+
+	state := draftState{
+		allies:      append([]int32{}, champSelect.Allies...),
+		enemies:     append([]int32{}, champSelect.Enemies...),
+		unavailable: make(map[int32]bool),
+		next:        made,
+		pick:        champID,
+		pickAt:      pickAt,
+	}
+	for _, id := range unavailableChampionIDs(champSelect) {
+		state.unavailable[id] = true
+	}
+	state.unavailable[champID] = true
+
+	return searchDraft(championStats, state, options.Side, options.Depth, options.Breadth), nil
+}
+
+// searchDraft searches depth picks past ours. Enemy picks before ours don't count towards depth.
+func searchDraft(championStats ChampionDataMap, state draftState, side Side, depth int, breadth int) float64 {
+	if state.next == state.pickAt {
+		return searchDraft(championStats, state.with(state.pick, false), side, depth, breadth)
+	}
+	beforeOurs := state.next < state.pickAt
+	if !beforeOurs && (depth <= 0 || state.next >= len(PickOrder)) {
+		return TeamWinProbability(championStats, state.allies, state.enemies)
+	}
+
+	// Picks still to search including this one
+	searched, childDepth := depth, depth-1
+	if beforeOurs {
+		searched, childDepth = depth+state.pickAt-state.next, depth
+	}
+
+	enemyPicks := PickOrder[state.next] != side
+	picks := likelyPicks(championStats, state, enemyPicks, searched, breadth)
+	if len(picks) == 0 {
+		return TeamWinProbability(championStats, state.allies, state.enemies)
+	}
+
+	best := math.Inf(1)
+	total, totalWeight := 0.0, 0.0
+	for _, pick := range picks {
+		nextState := state.with(pick.championID, enemyPicks)
+		value := searchDraft(championStats, nextState, side, childDepth, breadth)
+
+		if enemyPicks {
+			best = math.Min(best, value)
+		} else {
+			total += value * pick.weight
+			totalWeight += pick.weight
+		}
+	}
+
+	if enemyPicks {
+		return best
+	}
+	if totalWeight == 0 {
+		return TeamWinProbability(championStats, state.allies, state.enemies)
+	}
+	return total / totalWeight
+}
+
+type draftPick struct {
+	championID int32
+	weight     float64
+}
+
+// likelyPicks narrows down which champions are worth searching for the next pick.
+// For enemies those are the ones that lower our win probability the most, on the last searched pick
+// every champion is kept since it is cheap to evaluate. For teammates they are the most played champions.
+func likelyPicks(championStats ChampionDataMap, state draftState, enemyPicks bool, depth int, breadth int) []draftPick {
+	var picks []draftPick
+	for champID, data := range championStats {
+		if state.unavailable[champID] {
+			continue
+		}
+
+		if enemyPicks {
+			nextState := state.with(champID, true)
+			// Lower is better for the enemy, stored negated so both cases sort descending
+			picks = append(picks, draftPick{championID: champID, weight: -TeamWinProbability(championStats, nextState.allies, nextState.enemies)})
+		} else {
+			picks = append(picks, draftPick{championID: champID, weight: float64(data.Winrate.Games)})
+		}
+	}
+
+	sort.Slice(picks, func(i, j int) bool {
+		if picks[i].weight != picks[j].weight {
+			return picks[i].weight > picks[j].weight
+		}
+		return picks[i].championID < picks[j].championID
+	})
+
+	if enemyPicks && depth == 1 {
+		return picks
+	}
+	if breadth > 0 && len(picks) > breadth {
+		picks = picks[:breadth]
+	}
+	return picks
+}
+
+func (s draftState) with(champID int32, enemy bool) draftState {
+	next := draftState{
+		allies:      s.allies,
+		enemies:     s.enemies,
+		unavailable: make(map[int32]bool, len(s.unavailable)+1),
+		next:        s.next + 1,
+		pick:        s.pick,
+		pickAt:      s.pickAt,
+	}
+	for id := range s.unavailable {
+		next.unavailable[id] = true
+	}
+	next.unavailable[champID] = true
+
+	if enemy {
+		next.enemies = append(append([]int32{}, s.enemies...), champID)
+	} else {
+		next.allies = append(append([]int32{}, s.allies...), champID)
+	}
+	return next
+}
+
+// TeamWinProbability averages every interaction of the allies, with each other and against the enemies,
+// the same way calculateWinProbability does for a single champion
+func TeamWinProbability(championStats ChampionDataMap, allies, enemies []int32) float64 {
+	total := 0.0
+	count := 0
+
+	for i, ally := range allies {
+		for _, teammate := range allies[i+1:] {
+			total += createInteraction(teammate, championStats[ally].Synergies[teammate]).WinProbability
+			count++
+		}
+		for _, enemy := range enemies {
+			total += createInteraction(enemy, championStats[ally].Matchups[enemy]).WinProbability
+			count++
+		}
+	}
+
+	if count == 0 {
+		return 0.50
+	}
+	return total / float64(count)
+}
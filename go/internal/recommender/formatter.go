@@ -34,6 +34,15 @@ func FormatAnswer(ctx context.Context, queries *db.Queries, champSelect ChampSel
 	enemyChampsString := strings.Join(enemyChamps, ", ")
 	fmt.Println("Enemies:", enemyChampsString)
 
+	if champSelect.Rolled != 0 || len(champSelect.Bench) > 0 {
+		fmt.Println("Rolled:", IDToName(champsToIDs, champSelect.Rolled))
+		benchChamps := []string{}
+		for _, bench := range champSelect.Bench {
+			benchChamps = append(benchChamps, IDToName(champsToIDs, bench))
+		}
+		fmt.Println("Bench:", strings.Join(benchChamps, ", "))
+	}
+
 	fmt.Println("Recommended:")
 	for _, result := range results {
 		printChampionPerformance(champsToIDs, result)
@@ -59,12 +68,16 @@ func mapChampionsToIDs(ctx context.Context, queries *db.Queries) (map[string]int
 func printChampionPerformance(champsToIDs map[string]int32, champion ChampionPerformance) {
 	championName := IDToName(champsToIDs, champion.ChampionID)
 	winPercentage := probabilityAsPercentage(champion.WinProbability)
+	if champion.DraftWinProbability != 0 {
+		winPercentage += fmt.Sprintf(" (after draft %s)", probabilityAsPercentage(champion.DraftWinProbability))
+	}
 	matchupsString := printChampionInteractions(champsToIDs, champion.Matchups)
 	synergiesString := printChampionInteractions(champsToIDs, champion.Synergies)
 
-	fmt.Printf("%s: %s — MATCHUPS [ %s ] — SYNERGIES [ %s ]\n",
+	fmt.Printf("%s: %s — COUNTERABILITY %s — MATCHUPS [ %s ] — SYNERGIES [ %s ]\n",
 		championName,
 		winPercentage,
+		probabilityAsPercentage(champion.Counterability),
 		matchupsString,
 		synergiesString)
 }
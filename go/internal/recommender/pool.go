@@ -0,0 +1,103 @@
+package recommender
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"lol-champ-recommender/db"
+	"os"
+	"strings"
+)
+
+// ChampionPool is the set of champions a player actually plays
+type ChampionPool struct {
+	Name        string
+	ChampionIDs []int32
+	// Leaves champions outside the pool out of the recommendations entirely
+	Restrict bool
+	// Otherwise subtracted from the score of champions outside the pool so they rank lower
+	OutsidePenalty float64
+}
+
+// How a pool is written in config/champion_pools.json, keyed by pool name
+type championPoolConfig struct {
+	Champions      []string `json:"champions"`
+	Restrict       bool     `json:"restrict"`
+	OutsidePenalty float64  `json:"outside_penalty"`
+}
+
+func (p *ChampionPool) Contains(champID int32) bool {
+	return contains(p.ChampionIDs, champID)
+}
+
+// LoadChampionPool reads the named pool from a pool config file and resolves its champion names to Riot IDs
+func LoadChampionPool(ctx context.Context, queries *db.Queries, path string, name string) (*ChampionPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading champion pools: %w", err)
+	}
+
+	var pools map[string]championPoolConfig
+	if err := json.Unmarshal(data, &pools); err != nil {
+		return nil, fmt.Errorf("error unmarshalling champion pools: %w", err)
+	}
+
+	poolConfig, ok := pools[name]
+	if !ok {
+		return nil, fmt.Errorf("no champion pool named %q in %s", name, path)
+	}
+	if poolConfig.OutsidePenalty < 0 {
+		return nil, fmt.Errorf("outside_penalty for champion pool %q must not be negative", name)
+	}
+	if poolConfig.Restrict && poolConfig.OutsidePenalty != 0 {
+		return nil, fmt.Errorf("champion pool %q sets both restrict and outside_penalty, champions outside a restricted pool are never recommended", name)
+	}
+
+	champsToIDs, err := mapChampionsToIDs(ctx, queries)
+	if err != nil {
+		return nil, fmt.Errorf("error mapping champions to IDs: %v", err)
+	}
+
+	pool := &ChampionPool{
+		Name:           name,
+		Restrict:       poolConfig.Restrict,
+		OutsidePenalty: poolConfig.OutsidePenalty,
+	}
+	for _, championName := range poolConfig.Champions {
+		id, ok := NameToID(champsToIDs, championName)
+		if !ok {
+			return nil, fmt.Errorf("unknown champion %q in champion pool %q", championName, name)
+		}
+		pool.ChampionIDs = append(pool.ChampionIDs, id)
+	}
+
+	return pool, nil
+}
+
+// NameToID looks up a champion's Riot ID by name, ignoring case
+func NameToID(champions map[string]int32, name string) (int32, bool) {
+	for champName, champID := range champions {
+		if strings.EqualFold(champName, name) {
+			return champID, true
+		}
+	}
+	return 0, false
+}
+
+// NamesToIDs looks up the Riot IDs of champions by name, ignoring case
+func NamesToIDs(ctx context.Context, queries *db.Queries, names []string) ([]int32, error) {
+	champsToIDs, err := mapChampionsToIDs(ctx, queries)
+	if err != nil {
+		return nil, fmt.Errorf("error mapping champions to IDs: %v", err)
+	}
+
+	ids := make([]int32, 0, len(names))
+	for _, name := range names {
+		id, ok := NameToID(champsToIDs, name)
+		if !ok {
+			return nil, fmt.Errorf("unknown champion %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,163 @@
+package recommender
+
+import (
+	"fmt"
+	"sort"
+)
+
+type RecommendOptions struct {
+	// Restricts or re-ranks recommendations to a player's champion pool. Nil means every champion is considered.
+	Pool *ChampionPool
+	// How comfortable the player is on each champion, between 0 and 1. Missing champions count as 0.
+	Comfort map[int32]float64
+	// The most a champion's score is raised by comfort
+	ComfortWeight float64
+	// Scores champions by how they hold up against the rest of the draft instead of only the current picks
+	Draft *DraftOptions
+	// How much a champion's counterability is subtracted from its score, to prefer safe blind picks
+	CounterabilityWeight float64
+}
+
+func RecommendChampions(championStats ChampionDataMap, champSelect ChampSelect, options RecommendOptions) ([]ChampionPerformance, error) {
+	allChampIDs := allChampionIDs(championStats)
+	unavailableChampIDs := unavailableChampionIDs(champSelect)
+
+	var results []ChampionPerformance
+
+	for _, champID := range allChampIDs {
+		if contains(unavailableChampIDs, champID) {
+			continue
+		}
+
+		inPool := options.Pool == nil || options.Pool.Contains(champID)
+		if !inPool && options.Pool.Restrict {
+			continue
+		}
+
+		performance, err := championPerformance(champID, championStats, champSelect)
+		if err != nil {
+			return nil, fmt.Errorf("error getting performance for champion %d: %w", champID, err)
+		}
+
+		performance.Counterability = counterability(champID, championStats, performance, unavailableChampIDs)
+
+		performance.Score = performance.WinProbability
+		if options.Draft != nil {
+			performance.DraftWinProbability, err = draftWinProbability(championStats, champSelect, champID, *options.Draft)
+			if err != nil {
+				return nil, fmt.Errorf("error simulating draft for champion %d: %w", champID, err)
+			}
+			performance.Score = performance.DraftWinProbability
+		}
+		if !inPool {
+			performance.Score -= options.Pool.OutsidePenalty
+		}
+		performance.Score += options.ComfortWeight * options.Comfort[champID]
+		performance.Score -= options.CounterabilityWeight * performance.Counterability
+
+		results = append(results, performance)
+	}
+
+	sortResults(results)
+
+	return results, nil
+}
+
+func allChampionIDs(championStats ChampionDataMap) []int32 {
+	ids := make([]int32, 0, len(championStats))
+	for k := range championStats {
+		ids = append(ids, k)
+	}
+	return ids
+}
+
+func unavailableChampionIDs(champSelect ChampSelect) []int32 {
+	result := append([]int32{}, champSelect.Allies...)
+	result = append(result, champSelect.Enemies...)
+	result = append(result, champSelect.Bans...)
+	return result
+}
+
+func championPerformance(champID int32, championStats ChampionDataMap, champSelect ChampSelect) (ChampionPerformance, error) {
+	performance := ChampionPerformance{
+		ChampionID: champID,
+	}
+
+	synergies, err := championInteractions(champID, championStats[champID].Synergies, champSelect.Allies)
+	if err != nil {
+		return ChampionPerformance{}, err
+	}
+	performance.Synergies = synergies
+
+	matchups, err := championInteractions(champID, championStats[champID].Matchups, champSelect.Enemies)
+	if err != nil {
+		return ChampionPerformance{}, err
+	}
+	performance.Matchups = matchups
+
+	performance.WinProbability = calculateWinProbability(synergies, matchups)
+
+	return performance, nil
+}
+
+func championInteractions(champID int32, stats map[int32]WinStats, championIDs []int32) ([]ChampionInteraction, error) {
+	var interactions []ChampionInteraction
+
+	for _, targetID := range championIDs {
+		stat, ok := stats[targetID]
+		if !ok {
+			return nil, fmt.Errorf("stats not found for champion %d and target %d", champID, targetID)
+		}
+
+		interactions = append(interactions, createInteraction(targetID, stat))
+	}
+
+	return interactions, nil
+}
+
+func createInteraction(championID int32, stats WinStats) ChampionInteraction {
+	var winProbability float64
+	if stats.Games == 0 {
+		winProbability = 0.50
+	} else {
+		// Smoothing winrate
+		winProbability = float64(stats.Wins+5) / float64(stats.Games+10)
+	}
+
+	return ChampionInteraction{
+		ChampionID:     championID,
+		WinProbability: winProbability,
+		Wins:           stats.Wins,
+		Games:          stats.Games,
+	}
+}
+
+func calculateWinProbability(synergies, matchups []ChampionInteraction) float64 {
+	interactions := append(synergies, matchups...)
+	if len(interactions) == 0 {
+		return 0.50
+	}
+
+	total := 0.0
+	for _, interaction := range interactions {
+		total += interaction.WinProbability
+	}
+
+	return total / float64(len(interactions))
+}
+
+// Utils
+func contains(arr []int32, val int32) bool {
+	for _, v := range arr {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
+
+func sortResults(results []ChampionPerformance) {
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+}
@@ -8,8 +8,14 @@ import (
 type ChampionPerformance struct {
 	ChampionID     int32
 	WinProbability float64
-	Synergies      []ChampionInteraction
-	Matchups       []ChampionInteraction
+	// How much WinProbability drops against the enemy picks that counter this champion the most
+	Counterability float64
+	// Our team's expected win probability once the rest of the draft plays out, only set when simulating the draft
+	DraftWinProbability float64
+	// What results are ranked by, WinProbability adjusted for the draft and the player's preferences
+	Score     float64
+	Synergies []ChampionInteraction
+	Matchups  []ChampionInteraction
 }
 
 type ChampionInteraction struct {
@@ -19,7 +25,7 @@ type ChampionInteraction struct {
 	Games          int
 }
 
-// Taken from create_champion_stats/main.go
+// The champion_stats data, built by internal/stats
 type WinStats struct {
 	Wins  int `json:"wins"`
 	Games int `json:"games"`
@@ -38,6 +44,9 @@ type ChampSelect struct {
 	Bans    []int32
 	Allies  []int32
 	Enemies []int32
+	// ARAM only: the champions on the shared bench and the one we rolled, the only ones we can end up with
+	Bench  []int32
+	Rolled int32
 }
 
 // UnmarshalChampionStats converts JSON data to ChampionDataMap
@@ -0,0 +1,233 @@
+package reset
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"lol-champ-recommender/db"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Tables that can be reset. Champions are left alone since champions sync recreates them anyway.
+var Tables = []string{"matches", "champion_stats", "player_search_log", "backfill_cursors", "player_ranks"}
+
+// Scope is what a reset deletes. The zero value of each filter matches everything.
+type Scope struct {
+	Tables []string
+	// Only matches played on this server, e.g. NA1
+	Server string
+	// Only matches played on this patch, e.g. 14.1
+	Patch string
+	// Only rows older than this: matches by game start, champion stats by creation, the search log by search time,
+	// backfill cursors by their last update and player ranks by when they were fetched
+	Before time.Time
+}
+
+type TableCount struct {
+	Table string
+	Rows  int64
+}
+
+func (s Scope) Validate() error {
+	if len(s.Tables) == 0 {
+		return fmt.Errorf("no tables to reset")
+	}
+	for _, table := range s.Tables {
+		if !slices.Contains(Tables, table) {
+			return fmt.Errorf("unknown table %q, expected one of %v", table, Tables)
+		}
+		if table != "matches" && (s.Server != "" || s.Patch != "") {
+			return fmt.Errorf("server and patch only apply to matches, can't reset %s with them", table)
+		}
+	}
+	return nil
+}
+
+// Count returns how many rows in each table of the scope would be deleted
+func Count(ctx context.Context, queries *db.Queries, scope Scope) ([]TableCount, error) {
+	counts := make([]TableCount, 0, len(scope.Tables))
+	for _, table := range scope.Tables {
+		var rows int64
+		var err error
+		switch table {
+		case "matches":
+			rows, err = queries.CountMatchesInScope(ctx, scope.matchesParams())
+		case "champion_stats":
+			rows, err = queries.CountChampionStatsBefore(ctx, scope.before())
+		case "player_search_log":
+			rows, err = queries.CountPlayerSearchesBefore(ctx, scope.before())
+		case "backfill_cursors":
+			rows, err = queries.CountBackfillCursorsBefore(ctx, scope.before())
+		case "player_ranks":
+			rows, err = queries.CountPlayerRanksBefore(ctx, scope.before())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error counting %s: %w", table, err)
+		}
+		counts = append(counts, TableCount{Table: table, Rows: rows})
+	}
+	return counts, nil
+}
+
+// dump writes the rows that would be deleted to <dir>/<table>.jsonl, one JSON object per line
+func dump(ctx context.Context, queries *db.Queries, scope Scope, dir string) error {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return fmt.Errorf("error creating dump directory: %w", err)
+	}
+
+	for _, table := range scope.Tables {
+		var rows []any
+		switch table {
+		case "matches":
+			matches, err := queries.MatchesInScope(ctx, db.MatchesInScopeParams(scope.matchesParams()))
+			if err != nil {
+				return fmt.Errorf("error getting matches: %w", err)
+			}
+			for _, match := range matches {
+				rows = append(rows, match)
+			}
+		case "champion_stats":
+			snapshots, err := queries.ChampionStatsBefore(ctx, scope.before())
+			if err != nil {
+				return fmt.Errorf("error getting champion stats: %w", err)
+			}
+			for _, snapshot := range snapshots {
+				// Data is already JSON, written as is rather than base64 encoded
+				rows = append(rows, struct {
+					ID          int32
+					Data        json.RawMessage
+					LastMatchID int32
+					CreatedAt   pgtype.Timestamp
+					MinRank     pgtype.Int4
+					MaxRank     pgtype.Int4
+					QueueID     pgtype.Int4
+				}{snapshot.ID, snapshot.Data, snapshot.LastMatchID, snapshot.CreatedAt, snapshot.MinRank, snapshot.MaxRank, snapshot.QueueID})
+			}
+		case "player_search_log":
+			searches, err := queries.PlayerSearchesBefore(ctx, scope.before())
+			if err != nil {
+				return fmt.Errorf("error getting player searches: %w", err)
+			}
+			for _, search := range searches {
+				rows = append(rows, search)
+			}
+		case "backfill_cursors":
+			cursors, err := queries.BackfillCursorsBefore(ctx, scope.before())
+			if err != nil {
+				return fmt.Errorf("error getting backfill cursors: %w", err)
+			}
+			for _, cursor := range cursors {
+				rows = append(rows, cursor)
+			}
+		case "player_ranks":
+			ranks, err := queries.PlayerRanksBefore(ctx, scope.before())
+			if err != nil {
+				return fmt.Errorf("error getting player ranks: %w", err)
+			}
+			for _, rank := range ranks {
+				rows = append(rows, rank)
+			}
+		}
+
+		if err := writeJSONLines(filepath.Join(dir, table+".jsonl"), rows); err != nil {
+			return fmt.Errorf("error dumping %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+func writeJSONLines(path string, rows []any) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, row := range rows {
+		if err := encoder.Encode(row); err != nil {
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// Delete removes the rows in the scope from every table in one transaction and returns how many were deleted.
+// If dumpDir is set the rows are dumped there first, from the same snapshot the deletes see.
+func Delete(ctx context.Context, pool *pgxpool.Pool, scope Scope, dumpDir string) ([]TableCount, error) {
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead})
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	queries := db.New(tx)
+	if dumpDir != "" {
+		if err := dump(ctx, queries, scope, dumpDir); err != nil {
+			return nil, err
+		}
+	}
+
+	counts := make([]TableCount, 0, len(scope.Tables))
+	for _, table := range scope.Tables {
+		var rows int64
+		switch table {
+		case "matches":
+			rows, err = queries.DeleteMatchesInScope(ctx, db.DeleteMatchesInScopeParams(scope.matchesParams()))
+		case "champion_stats":
+			rows, err = queries.DeleteChampionStatsBefore(ctx, scope.before())
+		case "player_search_log":
+			rows, err = queries.DeletePlayerSearchesBefore(ctx, scope.before())
+			if err == nil {
+				// Otherwise the players still wouldn't be crawled again until their scheduled time
+				_, err = queries.DeletePlayerSchedulesBefore(ctx, scope.before())
+			}
+			if err == nil {
+				// Otherwise the next crawl would resume the checkpointed crawls of the players being forgotten
+				_, err = queries.DeleteCrawlCheckpointsBefore(ctx, scope.before())
+			}
+		case "backfill_cursors":
+			rows, err = queries.DeleteBackfillCursorsBefore(ctx, scope.before())
+		case "player_ranks":
+			rows, err = queries.DeletePlayerRanksBefore(ctx, scope.before())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error deleting from %s: %w", table, err)
+		}
+		counts = append(counts, TableCount{Table: table, Rows: rows})
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error committing reset: %w", err)
+	}
+	return counts, nil
+}
+
+// matchesParams converts the scope to the matches query parameters, which all share the same fields
+func (s Scope) matchesParams() db.CountMatchesInScopeParams {
+	return db.CountMatchesInScopeParams{
+		ServerID: pgtype.Text{String: s.Server, Valid: s.Server != ""},
+		Patch:    pgtype.Text{String: s.Patch, Valid: s.Patch != ""},
+		Before:   s.before(),
+	}
+}
+
+func (s Scope) before() pgtype.Timestamp {
+	if s.Before.IsZero() {
+		return pgtype.Timestamp{}
+	}
+	return pgtype.Timestamp{Time: s.Before, Valid: true}
+}
@@ -0,0 +1,234 @@
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"lol-champ-recommender/db"
+	"lol-champ-recommender/internal/rank"
+	"lol-champ-recommender/internal/recommender"
+	"lol-champ-recommender/internal/validate"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type BuildOptions struct {
+	// Only the oldest percentile of matches is used, leaving the rest unseen for evaluation
+	Percentile int32
+	// Only matches whose average rank is in the bracket are used, matches without one only count when the bracket is open
+	Bracket rank.Bracket
+	// Only matches from this queue are used when valid
+	QueueID pgtype.Int4
+	// Remakes are left out unless set. Aborted games have no winner and are always left out.
+	IncludeRemakes bool
+}
+
+type BuildResult struct {
+	LastMatchID int32
+	Matches     int
+	// How many matches were left out by outcome, e.g. remake, or as invalid when they failed validation
+	Excluded map[string]int64
+}
+
+// Build aggregates the matches picked by the options into a new champion_stats snapshot
+func Build(ctx context.Context, queries *db.Queries, options BuildOptions) (BuildResult, error) {
+	championStats, err := initChampionStats(ctx, queries)
+	if err != nil {
+		return BuildResult{}, fmt.Errorf("error initializing champion stats: %w", err)
+	}
+
+	lastMatchID, err := queries.MatchAtPercentileID(ctx, options.Percentile)
+	if err != nil {
+		return BuildResult{}, fmt.Errorf("error getting match at percentile %d: %w", options.Percentile, err)
+	}
+
+	match_ids, err := queries.MatchIDsUpToID(ctx, db.MatchIDsUpToIDParams{
+		ID:             lastMatchID,
+		MinRank:        options.Bracket.Min,
+		MaxRank:        options.Bracket.Max,
+		QueueID:        options.QueueID,
+		IncludeRemakes: options.IncludeRemakes,
+	})
+	if err != nil {
+		return BuildResult{}, fmt.Errorf("error getting all match ids: %w", err)
+	}
+
+	outcomes, err := queries.MatchOutcomesUpToID(ctx, db.MatchOutcomesUpToIDParams{
+		ID:      lastMatchID,
+		MinRank: options.Bracket.Min,
+		MaxRank: options.Bracket.Max,
+		QueueID: options.QueueID,
+	})
+	if err != nil {
+		return BuildResult{}, fmt.Errorf("error counting match outcomes: %w", err)
+	}
+	excluded := make(map[string]int64)
+	for _, outcome := range outcomes {
+		if outcome.Outcome == "completed" || (outcome.Outcome == "remake" && options.IncludeRemakes) {
+			continue
+		}
+		excluded[outcome.Outcome] = outcome.Count
+	}
+
+	champions := make(map[int32]bool, len(championStats))
+	for id := range championStats {
+		champions[id] = true
+	}
+	now := time.Now().UTC()
+	used := 0
+	for _, id := range match_ids {
+		match, err := queries.Match(ctx, id)
+		if err != nil {
+			return BuildResult{}, fmt.Errorf("error getting match with id %d: %w", id, err)
+		}
+		// Left out rather than failing the build, matches validate lists and quarantines them
+		if problems := validate.Match(match, champions, now); len(problems) > 0 {
+			excluded["invalid"]++
+			continue
+		}
+		used++
+
+		err = addMatchToChampionStats(championStats, match)
+		if err != nil {
+			return BuildResult{}, fmt.Errorf("error adding match to champion stats: %w", err)
+		}
+	}
+
+	json, err := championStatsToJSON(championStats)
+	if err != nil {
+		return BuildResult{}, fmt.Errorf("error converting champion stats to JSON: %w", err)
+	}
+
+	err = queries.CreateChampionStats(ctx, db.CreateChampionStatsParams{
+		Data:        json,
+		LastMatchID: lastMatchID,
+		MinRank:     options.Bracket.Min,
+		MaxRank:     options.Bracket.Max,
+		QueueID:     options.QueueID,
+	})
+	if err != nil {
+		return BuildResult{}, fmt.Errorf("error creating champion stats: %w", err)
+	}
+
+	return BuildResult{LastMatchID: lastMatchID, Matches: used, Excluded: excluded}, nil
+}
+
+func initChampionStats(ctx context.Context, queries *db.Queries) (recommender.ChampionDataMap, error) {
+	riotIDs, err := queries.AllChampionRiotIDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all champion ids: %w", err)
+	}
+
+	championStats := make(recommender.ChampionDataMap)
+	for _, id := range riotIDs {
+		championStats[id] = recommender.ChampionData{
+			Matchups:  make(map[int32]recommender.WinStats),
+			Synergies: make(map[int32]recommender.WinStats),
+		}
+		for _, id2 := range riotIDs {
+			championStats[id].Matchups[id2] = recommender.WinStats{}
+			championStats[id].Synergies[id2] = recommender.WinStats{}
+		}
+	}
+
+	return championStats, nil
+}
+
+func addMatchToChampionStats(championStats recommender.ChampionDataMap, match db.Match) error {
+	blueWins := match.WinningTeam == "blue"
+	blueChampions := []int32{match.Blue1ChampionID, match.Blue2ChampionID, match.Blue3ChampionID, match.Blue4ChampionID, match.Blue5ChampionID}
+	redChampions := []int32{match.Red1ChampionID, match.Red2ChampionID, match.Red3ChampionID, match.Red4ChampionID, match.Red5ChampionID}
+
+	// Process all champions
+	for i, champion := range append(blueChampions, redChampions...) {
+		isBlue := i < 5
+		err := addChampionToStats(championStats, champion, blueChampions, redChampions, isBlue, blueWins)
+		if err != nil {
+			return fmt.Errorf("failed to add champion to stats: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func addChampionToStats(championStats recommender.ChampionDataMap, championID int32, blueChampions, redChampions []int32, isBlue, blueWins bool) error {
+	if _, exists := championStats[championID]; !exists {
+		return fmt.Errorf("champion %d not found, champions sync might need to run first", championID)
+	}
+
+	// Process synergies
+	teammates := blueChampions
+	if !isBlue {
+		teammates = redChampions
+	}
+	won := (isBlue && blueWins) || (!isBlue && !blueWins)
+	for _, teammate := range teammates {
+		if teammate == championID {
+			continue
+		}
+
+		if _, exists := championStats[championID].Synergies[teammate]; !exists {
+			championStats[championID].Synergies[teammate] = recommender.WinStats{}
+		}
+
+		synergyStats := championStats[championID].Synergies[teammate]
+		synergyStats.Games++
+		if won {
+			synergyStats.Wins++
+		}
+		championStats[championID].Synergies[teammate] = synergyStats
+	}
+
+	// Process matchups
+	opponents := redChampions
+	if !isBlue {
+		opponents = blueChampions
+	}
+	for _, opponent := range opponents {
+		if _, exists := championStats[championID].Matchups[opponent]; !exists {
+			championStats[championID].Matchups[opponent] = recommender.WinStats{}
+		}
+
+		matchupStats := championStats[championID].Matchups[opponent]
+		matchupStats.Games++
+		if won {
+			matchupStats.Wins++
+		}
+		championStats[championID].Matchups[opponent] = matchupStats
+	}
+
+	// Update the overall winrate for the champion
+	cs := championStats[championID]
+
+	cs.Winrate.Games++
+	if won {
+		cs.Winrate.Wins++
+	}
+	championStats[championID] = cs
+
+	return nil
+}
+
+func championStatsToJSON(championStats recommender.ChampionDataMap) ([]byte, error) {
+	// Create a map to hold the JSON-friendly structure
+	jsonMap := make(map[string]interface{})
+
+	for champID, champData := range championStats {
+		champKey := fmt.Sprintf("%d", champID)
+		champJSON := map[string]interface{}{
+			"winrate":   champData.Winrate,
+			"matchups":  champData.Matchups,
+			"synergies": champData.Synergies,
+		}
+		jsonMap[champKey] = champJSON
+	}
+
+	// Marshal the map to JSON
+	jsonData, err := json.Marshal(jsonMap)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling champion stats to JSON: %w", err)
+	}
+
+	return jsonData, nil
+}
@@ -0,0 +1,83 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"lol-champ-recommender/db"
+	"lol-champ-recommender/internal/recommender"
+	"math"
+)
+
+type Evaluation struct {
+	Matches int
+	Correct int
+	// Mean squared error of the predicted blue win probability, lower is better
+	BrierScore float64
+	LogLoss    float64
+}
+
+func (e Evaluation) Accuracy() float64 {
+	if e.Matches == 0 {
+		return 0
+	}
+	return float64(e.Correct) / float64(e.Matches)
+}
+
+// Evaluate predicts the winner of every match the snapshot was not built from and scores the predictions.
+// Only matches in the snapshot's rank bracket and queue are predicted.
+func Evaluate(ctx context.Context, queries *db.Queries, snapshot db.ChampionStat) (Evaluation, error) {
+	championStats, err := recommender.UnmarshalChampionStats(snapshot.Data)
+	if err != nil {
+		return Evaluation{}, fmt.Errorf("error unmarshalling champion stats: %w", err)
+	}
+
+	matchIDs, err := queries.MatchIDsAfterID(ctx, db.MatchIDsAfterIDParams{
+		ID:      snapshot.LastMatchID,
+		MinRank: snapshot.MinRank,
+		MaxRank: snapshot.MaxRank,
+		QueueID: snapshot.QueueID,
+	})
+	if err != nil {
+		return Evaluation{}, fmt.Errorf("error getting held out match ids: %w", err)
+	}
+
+	var evaluation Evaluation
+	for _, id := range matchIDs {
+		match, err := queries.Match(ctx, id)
+		if err != nil {
+			return Evaluation{}, fmt.Errorf("error getting match with id %d: %w", id, err)
+		}
+
+		blueWinProbability := BlueWinProbability(championStats, match)
+		blueWon := match.WinningTeam == "blue"
+
+		actual := 0.0
+		if blueWon {
+			actual = 1.0
+		}
+		if (blueWinProbability > 0.5) == blueWon {
+			evaluation.Correct++
+		}
+
+		evaluation.Matches++
+		evaluation.BrierScore += math.Pow(blueWinProbability-actual, 2)
+		// Clamped so a confident wrong prediction doesn't make the loss infinite
+		p := math.Min(math.Max(blueWinProbability, 1e-6), 1-1e-6)
+		evaluation.LogLoss -= actual*math.Log(p) + (1-actual)*math.Log(1-p)
+	}
+
+	if evaluation.Matches > 0 {
+		evaluation.BrierScore /= float64(evaluation.Matches)
+		evaluation.LogLoss /= float64(evaluation.Matches)
+	}
+
+	return evaluation, nil
+}
+
+// BlueWinProbability averages how likely blue is to win from its own point of view and from red's
+func BlueWinProbability(championStats recommender.ChampionDataMap, match db.Match) float64 {
+	blue := []int32{match.Blue1ChampionID, match.Blue2ChampionID, match.Blue3ChampionID, match.Blue4ChampionID, match.Blue5ChampionID}
+	red := []int32{match.Red1ChampionID, match.Red2ChampionID, match.Red3ChampionID, match.Red4ChampionID, match.Red5ChampionID}
+
+	return (recommender.TeamWinProbability(championStats, blue, red) + 1 - recommender.TeamWinProbability(championStats, red, blue)) / 2
+}
@@ -0,0 +1,196 @@
+package validate
+
+import (
+	"context"
+	"fmt"
+	"lol-champ-recommender/db"
+	"lol-champ-recommender/internal/version"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Problems a stored match can have, in the order they are reported
+const (
+	ZeroChampion      = "zero_champion"
+	DuplicateChampion = "duplicate_champion"
+	UnknownChampion   = "unknown_champion"
+	WinningTeam       = "winning_team"
+	GameVersion       = "game_version"
+	GameStart         = "game_start"
+	GameDuration      = "game_duration"
+)
+
+var Problems = []string{ZeroChampion, DuplicateChampion, UnknownChampion, WinningTeam, GameVersion, GameStart, GameDuration}
+
+// Blind pick lets both teams pick the same champion, only duplicates within a team are wrong there
+var mirrorQueues = map[int32]bool{430: true}
+
+// League's release, no match started before it
+var firstGame = time.Date(2009, 10, 27, 0, 0, 0, 0, time.UTC)
+
+// Game starts are compared to now with this much slack, the crawler's time zone can skew them
+const clockSlack = 24 * time.Hour
+
+// Longer than any game that has been played
+const maxGameDuration = 24 * time.Hour
+
+// Match returns the problems with a stored match, none if it looks right.
+// champions holds every known champion id, unknown champions aren't checked when it is empty.
+func Match(match db.Match, champions map[int32]bool, now time.Time) []string {
+	var problems []string
+
+	blue := []int32{match.Blue1ChampionID, match.Blue2ChampionID, match.Blue3ChampionID, match.Blue4ChampionID, match.Blue5ChampionID}
+	red := []int32{match.Red1ChampionID, match.Red2ChampionID, match.Red3ChampionID, match.Red4ChampionID, match.Red5ChampionID}
+	zero, duplicate, unknown := false, false, false
+	seen := make(map[int32]int)
+	for i, champion := range append(blue, red...) {
+		team := i / len(blue)
+		if champion == 0 {
+			// A team with fewer than five participants is saved with zeros for the missing ones
+			zero = true
+			continue
+		}
+		if len(champions) > 0 && !champions[champion] {
+			unknown = true
+		}
+		if previous, ok := seen[champion]; ok && (previous == team || !mirrorQueues[match.QueueID]) {
+			duplicate = true
+		}
+		seen[champion] = team
+	}
+	if zero {
+		problems = append(problems, ZeroChampion)
+	}
+	if duplicate {
+		problems = append(problems, DuplicateChampion)
+	}
+	if unknown {
+		problems = append(problems, UnknownChampion)
+	}
+
+	// Aborted games are saved without a winner
+	validWinner := match.WinningTeam == "blue" || match.WinningTeam == "red" ||
+		(match.WinningTeam == "none" && match.Outcome == "aborted")
+	if !validWinner {
+		problems = append(problems, WinningTeam)
+	}
+
+	if _, err := version.Parse(match.GameVersion); err != nil {
+		problems = append(problems, GameVersion)
+	}
+
+	start := match.GameStart.Time
+	if !match.GameStart.Valid || start.Before(firstGame) || start.After(now.Add(clockSlack)) {
+		problems = append(problems, GameStart)
+	}
+	if match.GameDuration.Valid && (match.GameDuration.Int32 < 0 || time.Duration(match.GameDuration.Int32)*time.Second > maxGameDuration) {
+		problems = append(problems, GameDuration)
+	}
+
+	return problems
+}
+
+type Options struct {
+	// How many match ids to list for each problem
+	Examples int
+	// Move invalid matches to quarantined_matches
+	Quarantine bool
+	// How many matches to read at a time
+	PageSize int
+}
+
+type Report struct {
+	Matches int64
+	// Invalid matches by problem, a match with several problems counts for each of them
+	Counts   map[string]int64
+	Examples map[string][]string
+	Invalid  int64
+	// Matches moved to quarantined_matches
+	Quarantined int64
+	// Set when the champions table was empty and unknown champions weren't checked
+	NoChampions bool
+}
+
+// Run checks every stored match and quarantines the invalid ones if the options say to.
+// Each page is quarantined in its own transaction, so a failure keeps what was already moved.
+func Run(ctx context.Context, pool *pgxpool.Pool, options Options) (Report, error) {
+	queries := db.New(pool)
+	report := Report{
+		Counts:   make(map[string]int64),
+		Examples: make(map[string][]string),
+	}
+
+	championIDs, err := queries.AllChampionRiotIDs(ctx)
+	if err != nil {
+		return Report{}, fmt.Errorf("error getting champions: %w", err)
+	}
+	champions := make(map[int32]bool, len(championIDs))
+	for _, id := range championIDs {
+		champions[id] = true
+	}
+	report.NoChampions = len(champions) == 0
+
+	now := time.Now().UTC()
+	lastID := int32(0)
+	for {
+		matches, err := queries.MatchesAfterID(ctx, db.MatchesAfterIDParams{ID: lastID, Limit: int32(options.PageSize)})
+		if err != nil {
+			return Report{}, fmt.Errorf("error reading matches: %w", err)
+		}
+		if len(matches) == 0 {
+			break
+		}
+		lastID = matches[len(matches)-1].ID
+
+		invalid := make(map[string][]string)
+		for _, match := range matches {
+			report.Matches++
+			problems := Match(match, champions, now)
+			if len(problems) == 0 {
+				continue
+			}
+			report.Invalid++
+			invalid[match.MatchID] = problems
+			for _, problem := range problems {
+				report.Counts[problem]++
+				if len(report.Examples[problem]) < options.Examples {
+					report.Examples[problem] = append(report.Examples[problem], match.MatchID)
+				}
+			}
+		}
+
+		if options.Quarantine && len(invalid) > 0 {
+			quarantined, err := quarantine(ctx, pool, invalid)
+			if err != nil {
+				return Report{}, err
+			}
+			report.Quarantined += quarantined
+		}
+	}
+
+	return report, nil
+}
+
+func quarantine(ctx context.Context, pool *pgxpool.Pool, invalid map[string][]string) (int64, error) {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	queries := db.New(tx)
+	quarantined := int64(0)
+	for matchID, problems := range invalid {
+		moved, err := queries.QuarantineMatch(ctx, db.QuarantineMatchParams{MatchID: matchID, Reasons: problems})
+		if err != nil {
+			return 0, fmt.Errorf("error quarantining %s: %w", matchID, err)
+		}
+		quarantined += moved
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("error committing quarantine: %w", err)
+	}
+	return quarantined, nil
+}